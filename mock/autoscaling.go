@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/aws/autoscaling.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	autoscaling "github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAutoScalingClient is a mock of AutoScalingClient interface.
+type MockAutoScalingClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAutoScalingClientMockRecorder
+}
+
+// MockAutoScalingClientMockRecorder is the mock recorder for MockAutoScalingClient.
+type MockAutoScalingClientMockRecorder struct {
+	mock *MockAutoScalingClient
+}
+
+// NewMockAutoScalingClient creates a new mock instance.
+func NewMockAutoScalingClient(ctrl *gomock.Controller) *MockAutoScalingClient {
+	mock := &MockAutoScalingClient{ctrl: ctrl}
+	mock.recorder = &MockAutoScalingClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAutoScalingClient) EXPECT() *MockAutoScalingClientMockRecorder {
+	return m.recorder
+}
+
+// DescribeAutoScalingGroups mocks base method.
+func (m *MockAutoScalingClient) DescribeAutoScalingGroups(arg0 context.Context, arg1 *autoscaling.DescribeAutoScalingGroupsInput, arg2 ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeAutoScalingGroups", varargs...)
+	ret0, _ := ret[0].(*autoscaling.DescribeAutoScalingGroupsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeAutoScalingGroups indicates an expected call of DescribeAutoScalingGroups.
+func (mr *MockAutoScalingClientMockRecorder) DescribeAutoScalingGroups(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeAutoScalingGroups", reflect.TypeOf((*MockAutoScalingClient)(nil).DescribeAutoScalingGroups), varargs...)
+}
@@ -0,0 +1,192 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	autoScalingHealthStatusHealthy string = "Healthy"
+)
+
+type AutoScalingClient interface {
+	DescribeAutoScalingGroups(context.Context, *autoscaling.DescribeAutoScalingGroupsInput, ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error)
+}
+
+// AutoScalingExpectedGroup describes the expected attributes of an Auto Scaling group, for use with the AssertAutoScalingGroupAttributes
+// method. Any attribute that is left unset is not checked.
+type AutoScalingExpectedGroup struct {
+	// The minimum size of the group.
+	MinSize *int32
+	// The maximum size of the group.
+	MaxSize *int32
+	// The desired capacity of the group.
+	DesiredCapacity *int32
+	// The IDs of the subnets that the group launches instances in, which must match exactly.
+	SubnetIDs []string
+	// The availability zones that the group launches instances in, which must match exactly.
+	AvailabilityZones []string
+	// The health check type of the group, either "EC2" or "ELB".
+	HealthCheckType string
+	// The health check grace period of the group, in seconds.
+	HealthCheckGracePeriod *int32
+	// The ARNs of the target groups attached to the group, which must match exactly.
+	TargetGroupARNs []string
+	// The ID of the launch template used by the group, either directly or through a mixed instances policy.
+	LaunchTemplateID string
+	// The version of the launch template used by the group, e.g. "3" to assert that the group is pinned to a version rather than
+	// following "$Latest" or "$Default".
+	LaunchTemplateVersion string
+}
+
+/*
+AssertAutoScalingGroupAttributes asserts that an Auto Scaling group has the expected capacity, placement, health check and launch
+template settings.
+
+# Examples
+
+Assert that a group runs three to six instances across three subnets behind a load balancer, pinned to launch template version 4.
+
+	AssertAutoScalingGroupAttributes(t, ctx, client, "api", AutoScalingExpectedGroup{
+		MinSize:               aws.Int32(3),
+		MaxSize:               aws.Int32(6),
+		SubnetIDs:             []string{"subnet-0123456789abcdef0", "subnet-0123456789abcdef1", "subnet-0123456789abcdef2"},
+		HealthCheckType:       "ELB",
+		TargetGroupARNs:       []string{"arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/api/0123456789abcdef"},
+		LaunchTemplateVersion: "4",
+	})
+*/
+func AssertAutoScalingGroupAttributes(t *testing.T, ctx context.Context, client AutoScalingClient, groupName string, expected AutoScalingExpectedGroup) {
+	group, err := getAutoScalingGroupByNameE(ctx, client, groupName)
+	require.NoError(t, err)
+
+	if expected.MinSize != nil {
+		assert.Equal(t, expected.MinSize, group.MinSize, "Auto Scaling group '%s' does not have the right minimum size.", groupName)
+	}
+	if expected.MaxSize != nil {
+		assert.Equal(t, expected.MaxSize, group.MaxSize, "Auto Scaling group '%s' does not have the right maximum size.", groupName)
+	}
+	if expected.DesiredCapacity != nil {
+		assert.Equal(t, expected.DesiredCapacity, group.DesiredCapacity, "Auto Scaling group '%s' does not have the right desired capacity.", groupName)
+	}
+	if expected.SubnetIDs != nil {
+		assert.ElementsMatch(t, expected.SubnetIDs, getAutoScalingGroupSubnetIDs(group), "Auto Scaling group '%s' does not span the right subnets.", groupName)
+	}
+	if expected.AvailabilityZones != nil {
+		assert.ElementsMatch(t, expected.AvailabilityZones, group.AvailabilityZones, "Auto Scaling group '%s' does not span the right availability zones.", groupName)
+	}
+	if expected.HealthCheckType != "" {
+		assert.Equal(t, &expected.HealthCheckType, group.HealthCheckType, "Auto Scaling group '%s' does not have the right health check type.", groupName)
+	}
+	if expected.HealthCheckGracePeriod != nil {
+		assert.Equal(t, expected.HealthCheckGracePeriod, group.HealthCheckGracePeriod, "Auto Scaling group '%s' does not have the right health check grace period.", groupName)
+	}
+	if expected.TargetGroupARNs != nil {
+		assert.ElementsMatch(t, expected.TargetGroupARNs, group.TargetGroupARNs, "Auto Scaling group '%s' does not have the right target groups attached.", groupName)
+	}
+	if expected.LaunchTemplateID != "" || expected.LaunchTemplateVersion != "" {
+		launchTemplate := getAutoScalingGroupLaunchTemplate(group)
+		if !assert.NotNil(t, launchTemplate, "Auto Scaling group '%s' does not use a launch template.", groupName) {
+			return
+		}
+		if expected.LaunchTemplateID != "" {
+			assert.Equal(t, &expected.LaunchTemplateID, launchTemplate.LaunchTemplateId, "Auto Scaling group '%s' does not use the right launch template.", groupName)
+		}
+		if expected.LaunchTemplateVersion != "" {
+			assert.Equal(t, &expected.LaunchTemplateVersion, launchTemplate.Version, "Auto Scaling group '%s' does not use the right launch template version.", groupName)
+		}
+	}
+}
+
+// AssertAutoScalingGroupInstancesHealthy asserts that every instance of an Auto Scaling group that is in service is healthy. The
+// test failure lists every unhealthy instance.
+func AssertAutoScalingGroupInstancesHealthy(t *testing.T, ctx context.Context, client AutoScalingClient, groupName string) {
+	group, err := getAutoScalingGroupByNameE(ctx, client, groupName)
+	require.NoError(t, err)
+
+	var violations []string
+	for _, instance := range group.Instances {
+		if instance.LifecycleState != autoscalingtypes.LifecycleStateInService {
+			continue
+		}
+		if instance.HealthStatus == nil || *instance.HealthStatus != autoScalingHealthStatusHealthy {
+			healthStatus := ""
+			if instance.HealthStatus != nil {
+				healthStatus = *instance.HealthStatus
+			}
+			violations = append(violations, fmt.Sprintf("%s: %s", *instance.InstanceId, healthStatus))
+		}
+	}
+
+	assert.Empty(t, violations, "Auto Scaling group '%s' has unhealthy in-service instances:\n%s", groupName, strings.Join(violations, "\n"))
+}
+
+// GetAutoScalingGroupEC2InstancesE returns the EC2 instances of an Auto Scaling group, so that they can be passed to EC2 assertions
+// such as AssertEC2InstancesBalancedInSubnets.
+func GetAutoScalingGroupEC2InstancesE(ctx context.Context, client AutoScalingClient, ec2Client EC2Client, groupName string) (instances []types.Instance, err error) {
+	group, err := getAutoScalingGroupByNameE(ctx, client, groupName)
+	if err != nil {
+		return nil, err
+	}
+	var instanceIDs []string
+	for _, instance := range group.Instances {
+		if instance.InstanceId != nil {
+			instanceIDs = append(instanceIDs, *instance.InstanceId)
+		}
+	}
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+	return getEC2InstancesE(ctx, ec2Client, &ec2.DescribeInstancesInput{InstanceIds: instanceIDs})
+}
+
+// getAutoScalingGroupByNameE returns the Auto Scaling group with the given name.
+func getAutoScalingGroupByNameE(ctx context.Context, client AutoScalingClient, groupName string) (autoscalingtypes.AutoScalingGroup, error) {
+	input := &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []string{groupName},
+	}
+	output, err := client.DescribeAutoScalingGroups(ctx, input)
+	if err != nil {
+		return autoscalingtypes.AutoScalingGroup{}, err
+	}
+	if len(output.AutoScalingGroups) == 0 {
+		return autoscalingtypes.AutoScalingGroup{}, fmt.Errorf("auto scaling group with name '%s' was not found", groupName)
+	}
+	return output.AutoScalingGroups[0], nil
+}
+
+// getAutoScalingGroupSubnetIDs returns the IDs of the subnets that an Auto Scaling group launches instances in.
+func getAutoScalingGroupSubnetIDs(group autoscalingtypes.AutoScalingGroup) (subnetIDs []string) {
+	if group.VPCZoneIdentifier == nil {
+		return
+	}
+	for _, subnetID := range strings.Split(*group.VPCZoneIdentifier, ",") {
+		if subnetID = strings.TrimSpace(subnetID); subnetID != "" {
+			subnetIDs = append(subnetIDs, subnetID)
+		}
+	}
+	return
+}
+
+// getAutoScalingGroupLaunchTemplate returns the launch template used by an Auto Scaling group, either directly or through a mixed
+// instances policy, or nil if it does not use one.
+func getAutoScalingGroupLaunchTemplate(group autoscalingtypes.AutoScalingGroup) *autoscalingtypes.LaunchTemplateSpecification {
+	if group.LaunchTemplate != nil {
+		return group.LaunchTemplate
+	}
+	if group.MixedInstancesPolicy != nil && group.MixedInstancesPolicy.LaunchTemplate != nil {
+		return group.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification
+	}
+	return nil
+}
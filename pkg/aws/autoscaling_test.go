@@ -0,0 +1,183 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	autoscalingtypes "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAutoScalingGroup() autoscalingtypes.AutoScalingGroup {
+	return autoscalingtypes.AutoScalingGroup{
+		AutoScalingGroupName:   aws.String("api"),
+		MinSize:                aws.Int32(2),
+		MaxSize:                aws.Int32(6),
+		DesiredCapacity:        aws.Int32(2),
+		VPCZoneIdentifier:      aws.String("subnet-123456,subnet-654321"),
+		AvailabilityZones:      []string{"us-east-1a", "us-east-1b"},
+		HealthCheckType:        aws.String("ELB"),
+		HealthCheckGracePeriod: aws.Int32(300),
+		TargetGroupARNs:        []string{"arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/api/0123456789abcdef"},
+		LaunchTemplate: &autoscalingtypes.LaunchTemplateSpecification{
+			LaunchTemplateId: aws.String("lt-123456"),
+			Version:          aws.String("4"),
+		},
+		Instances: []autoscalingtypes.Instance{
+			{
+				InstanceId:     aws.String("i-123456"),
+				HealthStatus:   aws.String("Healthy"),
+				LifecycleState: autoscalingtypes.LifecycleStateInService,
+			},
+			{
+				InstanceId:     aws.String("i-654321"),
+				HealthStatus:   aws.String("Unhealthy"),
+				LifecycleState: autoscalingtypes.LifecycleStateTerminating,
+			},
+		},
+	}
+}
+
+func newTestAutoScalingClient(t *testing.T, groups ...autoscalingtypes.AutoScalingGroup) *mock.MockAutoScalingClient {
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockAutoScalingClient(ctrl)
+	input := &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []string{"api"},
+	}
+	clientMock.EXPECT().DescribeAutoScalingGroups(context.Background(), input).Times(1).Return(&autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: groups}, nil)
+	return clientMock
+}
+
+func TestAssertAutoScalingGroupAttributes_Match(t *testing.T) {
+	// Setup
+	clientMock := newTestAutoScalingClient(t, newTestAutoScalingGroup())
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertAutoScalingGroupAttributes(fakeTest, context.Background(), clientMock, "api", AutoScalingExpectedGroup{
+		MinSize:                aws.Int32(2),
+		MaxSize:                aws.Int32(6),
+		DesiredCapacity:        aws.Int32(2),
+		SubnetIDs:              []string{"subnet-654321", "subnet-123456"},
+		AvailabilityZones:      []string{"us-east-1a", "us-east-1b"},
+		HealthCheckType:        "ELB",
+		HealthCheckGracePeriod: aws.Int32(300),
+		TargetGroupARNs:        []string{"arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/api/0123456789abcdef"},
+		LaunchTemplateID:       "lt-123456",
+		LaunchTemplateVersion:  "4",
+	})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertAutoScalingGroupAttributes_NoMatch(t *testing.T) {
+	// Setup
+	clientMock := newTestAutoScalingClient(t, newTestAutoScalingGroup())
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertAutoScalingGroupAttributes(fakeTest, context.Background(), clientMock, "api", AutoScalingExpectedGroup{
+		SubnetIDs: []string{"subnet-123456"},
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertAutoScalingGroupAttributes_MixedInstancesPolicyLaunchTemplate(t *testing.T) {
+	// Setup
+	group := newTestAutoScalingGroup()
+	group.LaunchTemplate = nil
+	group.MixedInstancesPolicy = &autoscalingtypes.MixedInstancesPolicy{
+		LaunchTemplate: &autoscalingtypes.LaunchTemplate{
+			LaunchTemplateSpecification: &autoscalingtypes.LaunchTemplateSpecification{
+				LaunchTemplateId: aws.String("lt-123456"),
+				Version:          aws.String("$Latest"),
+			},
+		},
+	}
+	clientMock := newTestAutoScalingClient(t, group)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertAutoScalingGroupAttributes(fakeTest, context.Background(), clientMock, "api", AutoScalingExpectedGroup{
+		LaunchTemplateVersion: "4",
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertAutoScalingGroupInstancesHealthy_Match(t *testing.T) {
+	// Setup
+	clientMock := newTestAutoScalingClient(t, newTestAutoScalingGroup())
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertAutoScalingGroupInstancesHealthy(fakeTest, context.Background(), clientMock, "api")
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertAutoScalingGroupInstancesHealthy_NoMatch(t *testing.T) {
+	// Setup
+	group := newTestAutoScalingGroup()
+	group.Instances[1].LifecycleState = autoscalingtypes.LifecycleStateInService
+	clientMock := newTestAutoScalingClient(t, group)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertAutoScalingGroupInstancesHealthy(fakeTest, context.Background(), clientMock, "api")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestGetAutoScalingGroupEC2InstancesE(t *testing.T) {
+	// Setup
+	clientMock := newTestAutoScalingClient(t, newTestAutoScalingGroup())
+	ctrl := gomock.NewController(t)
+	ec2ClientMock := mock.NewMockEC2Client(ctrl)
+	input := &ec2.DescribeInstancesInput{
+		InstanceIds: []string{"i-123456", "i-654321"},
+	}
+	instances := []types.Instance{
+		{InstanceId: aws.String("i-123456"), SubnetId: aws.String("subnet-123456")},
+		{InstanceId: aws.String("i-654321"), SubnetId: aws.String("subnet-654321")},
+	}
+	ec2ClientMock.EXPECT().DescribeInstances(context.Background(), input).Times(1).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: instances}},
+	}, nil)
+
+	// Execute
+	actual, err := GetAutoScalingGroupEC2InstancesE(context.Background(), clientMock, ec2ClientMock, "api")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, instances, actual)
+}
+
+func TestGetAutoScalingGroupEC2InstancesE_NotFound(t *testing.T) {
+	// Setup
+	clientMock := newTestAutoScalingClient(t)
+	ctrl := gomock.NewController(t)
+	ec2ClientMock := mock.NewMockEC2Client(ctrl)
+
+	// Execute
+	_, err := GetAutoScalingGroupEC2InstancesE(context.Background(), clientMock, ec2ClientMock, "api")
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -28,6 +28,32 @@ type EC2Client interface {
 	DescribeTags(context.Context, *ec2.DescribeTagsInput, ...func(*ec2.Options)) (*ec2.DescribeTagsOutput, error)
 
 	DescribeSecurityGroups(context.Context, *ec2.DescribeSecurityGroupsInput, ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error)
+
+	GetEbsEncryptionByDefault(context.Context, *ec2.GetEbsEncryptionByDefaultInput, ...func(*ec2.Options)) (*ec2.GetEbsEncryptionByDefaultOutput, error)
+
+	DescribeSnapshots(context.Context, *ec2.DescribeSnapshotsInput, ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error)
+
+	DescribeSnapshotAttribute(context.Context, *ec2.DescribeSnapshotAttributeInput, ...func(*ec2.Options)) (*ec2.DescribeSnapshotAttributeOutput, error)
+
+	DescribeRouteTables(context.Context, *ec2.DescribeRouteTablesInput, ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error)
+
+	DescribeSubnets(context.Context, *ec2.DescribeSubnetsInput, ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+
+	DescribeVpcs(context.Context, *ec2.DescribeVpcsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
+
+	DescribeNetworkAcls(context.Context, *ec2.DescribeNetworkAclsInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkAclsOutput, error)
+
+	DescribeAddresses(context.Context, *ec2.DescribeAddressesInput, ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+
+	DescribeInstanceAttribute(context.Context, *ec2.DescribeInstanceAttributeInput, ...func(*ec2.Options)) (*ec2.DescribeInstanceAttributeOutput, error)
+
+	DescribeImages(context.Context, *ec2.DescribeImagesInput, ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error)
+
+	DescribeLaunchTemplateVersions(context.Context, *ec2.DescribeLaunchTemplateVersionsInput, ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error)
+
+	DescribeVpcEndpoints(context.Context, *ec2.DescribeVpcEndpointsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error)
+
+	DescribeFlowLogs(context.Context, *ec2.DescribeFlowLogsInput, ...func(*ec2.Options)) (*ec2.DescribeFlowLogsOutput, error)
 }
 
 // AssertEC2VolumeEncryptedInput is used as an input to the AssertEC2VolumeEncryptedE and AssertEC2VolumeEncrypted methods.
@@ -276,19 +302,87 @@ func getEC2VolumeByVolumeIDE(ctx context.Context, client EC2Client, VolumeID str
 	describeVolumesInput := &ec2.DescribeVolumesInput{
 		VolumeIds: []string{VolumeID},
 	}
-	describeVolumesOutput, err := client.DescribeVolumes(ctx, describeVolumesInput)
+	volumes, err := getEC2VolumesE(ctx, client, describeVolumesInput)
 	if err != nil {
 		return types.Volume{}, err
 	}
-	if len(describeVolumesOutput.Volumes) == 0 {
+	if len(volumes) == 0 {
 		err = fmt.Errorf("volume with ID '%s' was not found", VolumeID)
 		return types.Volume{}, err
 	}
-	volume := describeVolumesOutput.Volumes[0]
+	volume := volumes[0]
 	return volume, nil
 }
 
-func getEC2InstancesByTagE(ctx context.Context, client EC2Client, tags map[string][]string) (instances []types.Instance, err error) {
+// getEC2ImageByImageIDE returns the AMI with the given ID.
+func getEC2ImageByImageIDE(ctx context.Context, client EC2Client, imageID string) (types.Image, error) {
+	images, err := getEC2ImagesE(ctx, client, &ec2.DescribeImagesInput{ImageIds: []string{imageID}})
+	if err != nil {
+		return types.Image{}, err
+	}
+	if len(images) == 0 {
+		err = fmt.Errorf("image with ID '%s' was not found", imageID)
+		return types.Image{}, err
+	}
+	return images[0], nil
+}
+
+// awsPaginator is implemented by the paginators of the AWS SDK, e.g. ec2.DescribeInstancesPaginator. Options is the options type of
+// the service client.
+type awsPaginator[Output any, Options any] interface {
+	HasMorePages() bool
+	NextPage(context.Context, ...func(*Options)) (*Output, error)
+}
+
+// collectPagesE reads every page of a paginator and returns the items that getItems extracts from each page.
+func collectPagesE[Output any, Options any, Item any](ctx context.Context, paginator awsPaginator[Output, Options], getItems func(*Output) []Item) (items []Item, err error) {
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, getItems(output)...)
+	}
+	return
+}
+
+// getEC2InstancesE returns every instance matching the given input, reading all pages of results.
+func getEC2InstancesE(ctx context.Context, client EC2Client, input *ec2.DescribeInstancesInput) ([]types.Instance, error) {
+	return collectPagesE(ctx, ec2.NewDescribeInstancesPaginator(client, input), func(output *ec2.DescribeInstancesOutput) (instances []types.Instance) {
+		for _, reservation := range output.Reservations {
+			instances = append(instances, reservation.Instances...)
+		}
+		return
+	})
+}
+
+// getEC2VolumesE returns every volume matching the given input, reading all pages of results.
+func getEC2VolumesE(ctx context.Context, client EC2Client, input *ec2.DescribeVolumesInput) ([]types.Volume, error) {
+	return collectPagesE(ctx, ec2.NewDescribeVolumesPaginator(client, input), func(output *ec2.DescribeVolumesOutput) []types.Volume {
+		return output.Volumes
+	})
+}
+
+// getEC2ImagesE returns every AMI matching the given input, reading all pages of results.
+func getEC2ImagesE(ctx context.Context, client EC2Client, input *ec2.DescribeImagesInput) ([]types.Image, error) {
+	return collectPagesE(ctx, ec2.NewDescribeImagesPaginator(client, input), func(output *ec2.DescribeImagesOutput) []types.Image {
+		return output.Images
+	})
+}
+
+// getEC2SecurityGroupsE returns every security group matching the given filters, reading all pages of results.
+func getEC2SecurityGroupsE(ctx context.Context, client EC2Client, filters []types.Filter) ([]types.SecurityGroup, error) {
+	input := &ec2.DescribeSecurityGroupsInput{
+		Filters: filters,
+	}
+	return collectPagesE(ctx, ec2.NewDescribeSecurityGroupsPaginator(client, input), func(output *ec2.DescribeSecurityGroupsOutput) []types.SecurityGroup {
+		return output.SecurityGroups
+	})
+}
+
+// GetEC2InstancesByTagE returns all EC2 instances that have the given tags, reading all pages of results. Each key of the map is a
+// tag name, and an instance matches if the value of that tag is any of the values in the list.
+func GetEC2InstancesByTagE(ctx context.Context, client EC2Client, tags map[string][]string) (instances []types.Instance, err error) {
 	var filters []types.Filter
 
 	for tagName, tagValues := range tags {
@@ -302,17 +396,7 @@ func getEC2InstancesByTagE(ctx context.Context, client EC2Client, tags map[strin
 	describeInstancesInput := &ec2.DescribeInstancesInput{
 		Filters: filters,
 	}
-	output, err := client.DescribeInstances(ctx, describeInstancesInput)
-	if err != nil {
-		return nil, err
-	} else if output == nil {
-		return
-	}
-	reservations := output.Reservations
-	for _, reservation := range reservations {
-		instances = append(instances, reservation.Instances...)
-	}
-	return
+	return getEC2InstancesE(ctx, client, describeInstancesInput)
 }
 
 // AssertEC2InstancesBalancedInSubnets asserts that EC2 instances in a list are spread evenly throughout
@@ -338,6 +422,107 @@ func AssertEC2InstancesBalancedInSubnets(t *testing.T, ctx context.Context, inst
 	}
 }
 
+// AssertEC2InstancesBalancedOptions is a struct used for functional options for the AssertEC2InstancesBalancedInAvailabilityZones method.
+type AssertEC2InstancesBalancedOptions struct {
+	// The minimum number of availability zones that the instances must be spread across.
+	MinimumAvailabilityZones int
+	// Instances in any of these states are ignored. Defaults to the states of instances that are shutting down, terminated, stopping
+	// or stopped.
+	IgnoredInstanceStates []types.InstanceStateName
+}
+
+// defaultEC2IgnoredInstanceStates are the states of instances that are left out when checking a group of instances selected by tags,
+// as such instances are not serving and are often kept only until they are cleaned up.
+var defaultEC2IgnoredInstanceStates = []types.InstanceStateName{
+	types.InstanceStateNameShuttingDown,
+	types.InstanceStateNameTerminated,
+	types.InstanceStateNameStopping,
+	types.InstanceStateNameStopped,
+}
+
+// AssertEC2InstancesBalancedOptsFunc is a type used for functional options for the AssertEC2InstancesBalancedInAvailabilityZones method.
+type AssertEC2InstancesBalancedOptsFunc func(*AssertEC2InstancesBalancedOptions) error
+
+// WithEC2InstancesMinimumAvailabilityZones sets the minimum number of availability zones that instances must be spread across.
+func WithEC2InstancesMinimumAvailabilityZones(count int) AssertEC2InstancesBalancedOptsFunc {
+	return func(opts *AssertEC2InstancesBalancedOptions) error {
+		opts.MinimumAvailabilityZones = count
+		return nil
+	}
+}
+
+// WithEC2InstancesIgnoredStates causes instances in any of the given states to be left out when checking balance. This replaces the
+// default states, so calling it without any states checks instances in every state.
+func WithEC2InstancesIgnoredStates(states ...types.InstanceStateName) AssertEC2InstancesBalancedOptsFunc {
+	return func(opts *AssertEC2InstancesBalancedOptions) error {
+		opts.IgnoredInstanceStates = states
+		return nil
+	}
+}
+
+/*
+AssertEC2InstancesBalancedInAvailabilityZones asserts that the EC2 instances with the given tags are spread evenly throughout
+availability zones. Among the availability zones that have instances, the most loaded one may have at most one instance more than
+the least loaded one. Instances that are shutting down, terminated, stopping or stopped are ignored unless other states are set
+with WithEC2InstancesIgnoredStates.
+
+# Examples
+
+Assert that the running instances of a service span at least three availability zones and are balanced between them.
+
+	AssertEC2InstancesBalancedInAvailabilityZones(
+		t,
+		ctx,
+		client,
+		map[string][]string{"service": {"api"}},
+		WithEC2InstancesMinimumAvailabilityZones(3),
+	)
+*/
+func AssertEC2InstancesBalancedInAvailabilityZones(t *testing.T, ctx context.Context, client EC2Client, tags map[string][]string, optFns ...AssertEC2InstancesBalancedOptsFunc) {
+	opts := &AssertEC2InstancesBalancedOptions{
+		IgnoredInstanceStates: defaultEC2IgnoredInstanceStates,
+	}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	instances, err := GetEC2InstancesByTagE(ctx, client, tags)
+	require.NoError(t, err)
+
+	ignoredStates := make(map[types.InstanceStateName]bool)
+	for _, state := range opts.IgnoredInstanceStates {
+		ignoredStates[state] = true
+	}
+
+	availabilityZoneToInstanceCountMap := make(map[string]int)
+	for _, instance := range instances {
+		if instance.State != nil && ignoredStates[instance.State.Name] {
+			continue
+		}
+		if instance.Placement != nil && instance.Placement.AvailabilityZone != nil {
+			availabilityZoneToInstanceCountMap[*instance.Placement.AvailabilityZone] += 1
+		}
+	}
+
+	numAvailabilityZones := len(availabilityZoneToInstanceCountMap)
+	assert.Greater(t, numAvailabilityZones, 0, "No EC2 instances with the given tags were found in any availability zone.")
+	assert.GreaterOrEqual(t, numAvailabilityZones, opts.MinimumAvailabilityZones, "EC2 instances are spread across fewer availability zones than required.")
+	if numAvailabilityZones == 0 {
+		return
+	}
+	minInstancesPerAvailabilityZone := -1
+	for _, instanceCount := range availabilityZoneToInstanceCountMap {
+		if minInstancesPerAvailabilityZone < 0 || instanceCount < minInstancesPerAvailabilityZone {
+			minInstancesPerAvailabilityZone = instanceCount
+		}
+	}
+
+	for availabilityZone, instanceCount := range availabilityZoneToInstanceCountMap {
+		assert.LessOrEqual(t, instanceCount, minInstancesPerAvailabilityZone+1, "Availability zone %s is overloaded with %d EC2 instances, while another availability zone has %d.", availabilityZone, instanceCount, minInstancesPerAvailabilityZone)
+	}
+}
+
 // CreateFiltersFromMap is a utility method that creates a Filter object from a map of strings.
 // It's designed to make creating filter objects easier without worrying about pointers and the like.
 func CreateFiltersFromMap(input map[string][]string) (output []types.Filter) {
@@ -357,21 +542,37 @@ func CreateFiltersFromMap(input map[string][]string) (output []types.Filter) {
 // is found, it will return a nil value.
 func GetEC2SecurityGroupByName(ctx context.Context, client EC2Client, name string) (securityGroup *types.SecurityGroup, err error) {
 	filterKey := "group-name"
-	input := &ec2.DescribeSecurityGroupsInput{
-		Filters: []types.Filter{
-			{
-				Name:   &filterKey,
-				Values: []string{name},
-			},
+	filters := []types.Filter{
+		{
+			Name:   &filterKey,
+			Values: []string{name},
 		},
 	}
-	output, err := client.DescribeSecurityGroups(ctx, input)
+	securityGroups, err := getEC2SecurityGroupsE(ctx, client, filters)
 	if err != nil {
 		return nil, err
 	}
-	if output == nil {
+	if len(securityGroups) == 0 {
 		return nil, err
 	}
-	securityGroup = &output.SecurityGroups[0]
+	securityGroup = &securityGroups[0]
 	return
 }
+
+// stringInSlice returns true if a string is equal to any of the values in a slice.
+func stringInSlice(value string, values []string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique appends a value to a slice of strings if it is not already present.
+func appendUnique(values []string, value string) []string {
+	if stringInSlice(value, values) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,259 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertEC2InstanceImageOptions is a struct used for functional options for the AssertEC2InstanceImage method. Either an instance
+// ID or a set of instance tags must be set. Any check that is left unset is not performed.
+type AssertEC2InstanceImageOptions struct {
+	// The ID of the instance to check.
+	InstanceID string
+	// Tags used to select the instances to check, in the format accepted by the GetEC2InstancesByTagE method.
+	InstanceTags map[string][]string
+	// The IDs of the AWS accounts that may own the AMIs.
+	AllowedOwnerIDs []string
+	// A regular expression that the name of the AMIs must match.
+	NamePattern *regexp.Regexp
+	// For each tag key, a regular expression that the value of that tag on the AMIs must match.
+	TagPatterns map[string]*regexp.Regexp
+	// Whether AMIs that have passed their deprecation time are rejected.
+	NotDeprecated bool
+	// The maximum time since the AMIs were created.
+	MaximumAge time.Duration
+	// The function used to get the current time when checking deprecation and age. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// AssertEC2InstanceImageOptsFunc is a type used for functional options for the AssertEC2InstanceImage method.
+type AssertEC2InstanceImageOptsFunc func(*AssertEC2InstanceImageOptions) error
+
+// WithEC2ImageInstanceID sets the ID of the instance whose AMI is checked.
+func WithEC2ImageInstanceID(instanceID string) AssertEC2InstanceImageOptsFunc {
+	return func(opts *AssertEC2InstanceImageOptions) error {
+		opts.InstanceID = instanceID
+		return nil
+	}
+}
+
+// WithEC2ImageInstanceTags sets the tags used to select the instances whose AMIs are checked.
+func WithEC2ImageInstanceTags(tags map[string][]string) AssertEC2InstanceImageOptsFunc {
+	return func(opts *AssertEC2InstanceImageOptions) error {
+		opts.InstanceTags = tags
+		return nil
+	}
+}
+
+// WithEC2ImageAllowedOwnerIDs sets the IDs of the AWS accounts that may own the AMIs.
+func WithEC2ImageAllowedOwnerIDs(ownerIDs ...string) AssertEC2InstanceImageOptsFunc {
+	return func(opts *AssertEC2InstanceImageOptions) error {
+		opts.AllowedOwnerIDs = append(opts.AllowedOwnerIDs, ownerIDs...)
+		return nil
+	}
+}
+
+// WithEC2ImageNamePattern sets a regular expression that the name of the AMIs must match.
+func WithEC2ImageNamePattern(pattern string) AssertEC2InstanceImageOptsFunc {
+	return func(opts *AssertEC2InstanceImageOptions) error {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		opts.NamePattern = re
+		return nil
+	}
+}
+
+// WithEC2ImageTagPattern sets a regular expression that the value of a tag on the AMIs must match. Tags on AMIs shared from
+// another account are not visible, so this is best used with AMIs owned by the account of the client.
+func WithEC2ImageTagPattern(key string, pattern string) AssertEC2InstanceImageOptsFunc {
+	return func(opts *AssertEC2InstanceImageOptions) error {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		if opts.TagPatterns == nil {
+			opts.TagPatterns = make(map[string]*regexp.Regexp)
+		}
+		opts.TagPatterns[key] = re
+		return nil
+	}
+}
+
+// WithEC2ImageNotDeprecated causes AMIs that have passed their deprecation time to be rejected.
+func WithEC2ImageNotDeprecated() AssertEC2InstanceImageOptsFunc {
+	return func(opts *AssertEC2InstanceImageOptions) error {
+		opts.NotDeprecated = true
+		return nil
+	}
+}
+
+// WithEC2ImageMaximumAge sets the maximum time since the AMIs were created.
+func WithEC2ImageMaximumAge(maximumAge time.Duration) AssertEC2InstanceImageOptsFunc {
+	return func(opts *AssertEC2InstanceImageOptions) error {
+		if maximumAge <= 0 {
+			return fmt.Errorf("maximum age must be positive, got %s", maximumAge)
+		}
+		opts.MaximumAge = maximumAge
+		return nil
+	}
+}
+
+// WithEC2ImageNow sets the function used to get the current time when checking deprecation and age, which is useful for tests.
+func WithEC2ImageNow(now func() time.Time) AssertEC2InstanceImageOptsFunc {
+	return func(opts *AssertEC2InstanceImageOptions) error {
+		opts.Now = now
+		return nil
+	}
+}
+
+/*
+AssertEC2InstanceImage asserts that the AMI that an instance, or every instance with a set of tags, was launched from has the
+expected provenance and is fresh enough. Every AMI is retrieved once, and the test failure lists every violation of every
+instance. An AMI that can no longer be described, e.g. because it was deregistered, is reported as a violation.
+
+# Examples
+
+Assert that a service's instances run AMIs built by the image pipeline account in the last 30 days.
+
+	AssertEC2InstanceImage(
+		t,
+		ctx,
+		client,
+		WithEC2ImageInstanceTags(map[string][]string{"service": {"api"}}),
+		WithEC2ImageAllowedOwnerIDs("123456789012"),
+		WithEC2ImageNamePattern(`^golden-al2023-`),
+		WithEC2ImageNotDeprecated(),
+		WithEC2ImageMaximumAge(30*24*time.Hour),
+	)
+*/
+func AssertEC2InstanceImage(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2InstanceImageOptsFunc) {
+	opts := &AssertEC2InstanceImageOptions{
+		Now: time.Now,
+	}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	instances := getEC2InstancesByIDOrTags(t, ctx, client, opts.InstanceID, opts.InstanceTags)
+
+	var imageIDs []string
+	for _, instance := range instances {
+		if instance.ImageId != nil {
+			imageIDs = appendUnique(imageIDs, *instance.ImageId)
+		}
+	}
+	images := make(map[string]types.Image)
+	if len(imageIDs) > 0 {
+		imageList, err := getEC2ImagesE(ctx, client, &ec2.DescribeImagesInput{ImageIds: imageIDs})
+		require.NoError(t, err)
+		for _, image := range imageList {
+			images[*image.ImageId] = image
+		}
+	}
+
+	now := opts.Now()
+	var violations []string
+	for _, instance := range instances {
+		if instance.ImageId == nil {
+			violations = append(violations, fmt.Sprintf("%s: does not have an AMI ID", *instance.InstanceId))
+			continue
+		}
+		prefix := fmt.Sprintf("%s (%s)", *instance.InstanceId, *instance.ImageId)
+		image, ok := images[*instance.ImageId]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("%s: AMI could not be described", prefix))
+			continue
+		}
+		imageViolations, err := getEC2ImageViolationsE(image, opts, now)
+		require.NoError(t, err)
+		violations = append(violations, prefixViolations(prefix, imageViolations)...)
+	}
+
+	assert.Empty(t, violations, "EC2 instances running AMIs that are not allowed were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// getEC2ImageViolationsE returns a description of every way in which an AMI does not meet the checks in the options. An error is
+// returned if a creation or deprecation time returned by the EC2 API cannot be parsed.
+func getEC2ImageViolationsE(image types.Image, opts *AssertEC2InstanceImageOptions, now time.Time) (violations []string, err error) {
+	if len(opts.AllowedOwnerIDs) > 0 {
+		ownerID := ""
+		if image.OwnerId != nil {
+			ownerID = *image.OwnerId
+		}
+		if !stringInSlice(ownerID, opts.AllowedOwnerIDs) {
+			violations = append(violations, fmt.Sprintf("owned by account '%s', which is not allowed", ownerID))
+		}
+	}
+
+	if opts.NamePattern != nil {
+		name := ""
+		if image.Name != nil {
+			name = *image.Name
+		}
+		if !opts.NamePattern.MatchString(name) {
+			violations = append(violations, fmt.Sprintf("name '%s' does not match the pattern '%s'", name, opts.NamePattern))
+		}
+	}
+
+	if len(opts.TagPatterns) > 0 {
+		tags := make(map[string]string)
+		for _, tag := range image.Tags {
+			if tag.Key != nil && tag.Value != nil {
+				tags[*tag.Key] = *tag.Value
+			}
+		}
+		var keys []string
+		for key := range opts.TagPatterns {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := tags[key]
+			if !ok {
+				violations = append(violations, fmt.Sprintf("tag '%s' is missing", key))
+			} else if !opts.TagPatterns[key].MatchString(value) {
+				violations = append(violations, fmt.Sprintf("tag '%s' has value '%s', which does not match the pattern '%s'", key, value, opts.TagPatterns[key]))
+			}
+		}
+	}
+
+	if opts.NotDeprecated && image.DeprecationTime != nil {
+		deprecationTime, err := time.Parse(time.RFC3339, *image.DeprecationTime)
+		if err != nil {
+			return nil, err
+		}
+		if !now.Before(deprecationTime) {
+			violations = append(violations, fmt.Sprintf("deprecated since %s", *image.DeprecationTime))
+		}
+	}
+
+	if opts.MaximumAge > 0 {
+		if image.CreationDate == nil {
+			violations = append(violations, "does not have a creation date")
+		} else {
+			creationDate, err := time.Parse(time.RFC3339, *image.CreationDate)
+			if err != nil {
+				return nil, err
+			}
+			if age := now.Sub(creationDate); age > opts.MaximumAge {
+				violations = append(violations, fmt.Sprintf("created %s ago, which is older than the maximum age of %s", age.Round(time.Hour), opts.MaximumAge))
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,157 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEC2ImageNow() time.Time {
+	return time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func newTestEC2Image() types.Image {
+	return types.Image{
+		ImageId:         aws.String("ami-123456"),
+		OwnerId:         aws.String("123456789012"),
+		Name:            aws.String("golden-al2023-20240215"),
+		CreationDate:    aws.String("2024-02-15T10:00:00.000Z"),
+		DeprecationTime: aws.String("2024-08-15T10:00:00.000Z"),
+		Tags: []types.Tag{
+			{Key: aws.String("pipeline"), Value: aws.String("golden")},
+		},
+	}
+}
+
+func TestGetEC2ImageViolationsE_Compliant(t *testing.T) {
+	// Setup
+	opts := &AssertEC2InstanceImageOptions{}
+	for _, optFn := range []AssertEC2InstanceImageOptsFunc{
+		WithEC2ImageAllowedOwnerIDs("123456789012"),
+		WithEC2ImageNamePattern(`^golden-al2023-`),
+		WithEC2ImageTagPattern("pipeline", `^golden$`),
+		WithEC2ImageNotDeprecated(),
+		WithEC2ImageMaximumAge(30 * 24 * time.Hour),
+	} {
+		require.NoError(t, optFn(opts))
+	}
+
+	// Execute
+	violations, err := getEC2ImageViolationsE(newTestEC2Image(), opts, newTestEC2ImageNow())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestGetEC2ImageViolationsE_AllViolations(t *testing.T) {
+	// Setup
+	opts := &AssertEC2InstanceImageOptions{}
+	for _, optFn := range []AssertEC2InstanceImageOptsFunc{
+		WithEC2ImageAllowedOwnerIDs("210987654321"),
+		WithEC2ImageNamePattern(`^golden-ubuntu-`),
+		WithEC2ImageTagPattern("approved", `^true$`),
+		WithEC2ImageNotDeprecated(),
+		WithEC2ImageMaximumAge(7 * 24 * time.Hour),
+	} {
+		require.NoError(t, optFn(opts))
+	}
+
+	// Execute
+	violations, err := getEC2ImageViolationsE(newTestEC2Image(), opts, newTestEC2ImageNow().AddDate(1, 0, 0))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, violations, 5)
+}
+
+func TestAssertEC2InstanceImage_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{
+					Instances: []types.Instance{
+						{
+							InstanceId: aws.String("i-123456"),
+							ImageId:    aws.String("ami-123456"),
+						},
+					},
+				},
+			},
+		}, nil)
+	clientMock.EXPECT().
+		DescribeImages(ctx, &ec2.DescribeImagesInput{ImageIds: []string{"ami-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeImagesOutput{Images: []types.Image{newTestEC2Image()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceImage(fakeTest, ctx, clientMock,
+		WithEC2ImageInstanceID("i-123456"),
+		WithEC2ImageAllowedOwnerIDs("123456789012"),
+		WithEC2ImageNotDeprecated(),
+		WithEC2ImageMaximumAge(30*24*time.Hour),
+		WithEC2ImageNow(newTestEC2ImageNow),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceImage_FleetNoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{Filters: CreateFiltersFromMap(map[string][]string{"tag:service": {"api"}})}).
+		Times(1).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{
+					Instances: []types.Instance{
+						{
+							InstanceId: aws.String("i-123456"),
+							ImageId:    aws.String("ami-123456"),
+						},
+						{
+							InstanceId: aws.String("i-654321"),
+							ImageId:    aws.String("ami-deregistered"),
+						},
+					},
+				},
+			},
+		}, nil)
+	clientMock.EXPECT().
+		DescribeImages(ctx, &ec2.DescribeImagesInput{ImageIds: []string{"ami-123456", "ami-deregistered"}}).
+		Times(1).
+		Return(&ec2.DescribeImagesOutput{Images: []types.Image{newTestEC2Image()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceImage(fakeTest, ctx, clientMock,
+		WithEC2ImageInstanceTags(map[string][]string{"service": {"api"}}),
+		WithEC2ImageAllowedOwnerIDs("123456789012"),
+		WithEC2ImageNow(newTestEC2ImageNow),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
@@ -0,0 +1,364 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	instanceIDFilterName string = "instance-id"
+)
+
+// AssertEC2MetadataOptions is a struct used for functional options for the AssertEC2InstanceMetadataOptions method.
+// Either an instance ID or a set of instance tags must be set. Any expected attribute that is left unset is not checked.
+type AssertEC2MetadataOptions struct {
+	// The ID of the instance to check.
+	InstanceID string
+	// Tags used to select the instances to check, in the format accepted by the GetEC2InstancesByTagE method.
+	InstanceTags map[string][]string
+	// Whether session tokens (IMDSv2) must be required.
+	HTTPTokensRequired bool
+	// The maximum allowed hop limit for metadata PUT responses.
+	MaximumHopLimit *int32
+	// Whether the metadata endpoint must be enabled (true) or disabled (false).
+	HTTPEndpointEnabled *bool
+	// Whether access to instance tags from the metadata endpoint must be enabled (true) or disabled (false).
+	InstanceMetadataTagsEnabled *bool
+}
+
+// AssertEC2MetadataOptsFunc is a type used for functional options for the AssertEC2InstanceMetadataOptions method.
+type AssertEC2MetadataOptsFunc func(*AssertEC2MetadataOptions) error
+
+// WithEC2MetadataInstanceID sets the ID of the instance whose metadata options are checked.
+func WithEC2MetadataInstanceID(instanceID string) AssertEC2MetadataOptsFunc {
+	return func(opts *AssertEC2MetadataOptions) error {
+		opts.InstanceID = instanceID
+		return nil
+	}
+}
+
+// WithEC2MetadataInstanceTags causes the metadata options of every instance with the given tags to be checked.
+func WithEC2MetadataInstanceTags(tags map[string][]string) AssertEC2MetadataOptsFunc {
+	return func(opts *AssertEC2MetadataOptions) error {
+		opts.InstanceTags = tags
+		return nil
+	}
+}
+
+// WithEC2MetadataHTTPTokensRequired asserts that instances require session tokens, i.e. only IMDSv2 may be used.
+func WithEC2MetadataHTTPTokensRequired() AssertEC2MetadataOptsFunc {
+	return func(opts *AssertEC2MetadataOptions) error {
+		opts.HTTPTokensRequired = true
+		return nil
+	}
+}
+
+// WithEC2MetadataMaximumHopLimit asserts that the hop limit for metadata PUT responses is no greater than the given value.
+func WithEC2MetadataMaximumHopLimit(hopLimit int32) AssertEC2MetadataOptsFunc {
+	return func(opts *AssertEC2MetadataOptions) error {
+		opts.MaximumHopLimit = &hopLimit
+		return nil
+	}
+}
+
+// WithEC2MetadataHTTPEndpointEnabled asserts that the metadata endpoint is either enabled or disabled.
+func WithEC2MetadataHTTPEndpointEnabled(enabled bool) AssertEC2MetadataOptsFunc {
+	return func(opts *AssertEC2MetadataOptions) error {
+		opts.HTTPEndpointEnabled = &enabled
+		return nil
+	}
+}
+
+// WithEC2MetadataInstanceTagsEnabled asserts that access to instance tags from the metadata endpoint is either enabled or disabled.
+func WithEC2MetadataInstanceTagsEnabled(enabled bool) AssertEC2MetadataOptsFunc {
+	return func(opts *AssertEC2MetadataOptions) error {
+		opts.InstanceMetadataTagsEnabled = &enabled
+		return nil
+	}
+}
+
+/*
+AssertEC2InstanceMetadataOptions asserts that the instance metadata service (IMDS) options of a single instance, or of every instance
+with a set of tags, match the expected values passed as functional options. Instances that are shutting down, terminated, stopping or
+stopped are not checked when selecting by tags.
+
+# Examples
+
+Assert that every instance of a service requires IMDSv2 and cannot be reached from containers through an extra network hop.
+
+	AssertEC2InstanceMetadataOptions(
+		t,
+		ctx,
+		client,
+		WithEC2MetadataInstanceTags(map[string][]string{"service": {"api"}}),
+		WithEC2MetadataHTTPTokensRequired(),
+		WithEC2MetadataMaximumHopLimit(1),
+	)
+*/
+func AssertEC2InstanceMetadataOptions(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2MetadataOptsFunc) {
+	opts := &AssertEC2MetadataOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	instances := getEC2InstancesByIDOrTags(t, ctx, client, opts.InstanceID, opts.InstanceTags)
+
+	for _, instance := range instances {
+		instanceID := ""
+		if instance.InstanceId != nil {
+			instanceID = *instance.InstanceId
+		}
+		metadataOptions := instance.MetadataOptions
+		if !assert.NotNil(t, metadataOptions, "Instance '%s' does not have any metadata options.", instanceID) {
+			continue
+		}
+		if opts.HTTPTokensRequired {
+			assert.Equal(t, types.HttpTokensStateRequired, metadataOptions.HttpTokens, "Instance '%s' does not require IMDSv2 session tokens.", instanceID)
+		}
+		if opts.MaximumHopLimit != nil {
+			if assert.NotNil(t, metadataOptions.HttpPutResponseHopLimit, "Instance '%s' does not have a metadata hop limit.", instanceID) {
+				assert.LessOrEqual(t, *metadataOptions.HttpPutResponseHopLimit, *opts.MaximumHopLimit, "Instance '%s' has a metadata hop limit that is too high.", instanceID)
+			}
+		}
+		if opts.HTTPEndpointEnabled != nil {
+			expectedState := types.InstanceMetadataEndpointStateDisabled
+			if *opts.HTTPEndpointEnabled {
+				expectedState = types.InstanceMetadataEndpointStateEnabled
+			}
+			assert.Equal(t, expectedState, metadataOptions.HttpEndpoint, "Instance '%s' has an unexpected metadata endpoint state.", instanceID)
+		}
+		if opts.InstanceMetadataTagsEnabled != nil {
+			expectedState := types.InstanceMetadataTagsStateDisabled
+			if *opts.InstanceMetadataTagsEnabled {
+				expectedState = types.InstanceMetadataTagsStateEnabled
+			}
+			assert.Equal(t, expectedState, metadataOptions.InstanceMetadataTags, "Instance '%s' has an unexpected instance tags in metadata state.", instanceID)
+		}
+	}
+}
+
+// getEC2InstancesByIDOrTags returns either the single instance with the given ID, or every instance with the given tags that is not
+// shutting down, terminated, stopping or stopped. The test fails immediately if neither or both are set, if an error occurs, or if no
+// instances are found.
+func getEC2InstancesByIDOrTags(t *testing.T, ctx context.Context, client EC2Client, instanceID string, tags map[string][]string) []types.Instance {
+	require.True(t, (instanceID == "") != (len(tags) == 0), "Exactly one of an instance ID or instance tags must be set by the passed functional options.")
+
+	if instanceID != "" {
+		instance, err := getEC2InstanceByInstanceIDE(ctx, client, instanceID)
+		require.NoError(t, err)
+		return []types.Instance{instance}
+	}
+
+	taggedInstances, err := GetEC2InstancesByTagE(ctx, client, tags)
+	require.NoError(t, err)
+	var instances []types.Instance
+	for _, instance := range taggedInstances {
+		if instance.State != nil && ec2InstanceStateInSlice(instance.State.Name, defaultEC2IgnoredInstanceStates) {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	require.NotEmpty(t, instances, "No EC2 instances with the given tags were found, other than stopped or terminated ones.")
+	return instances
+}
+
+// ec2InstanceStateInSlice returns true if an instance state is equal to any of the states in a slice.
+func ec2InstanceStateInSlice(state types.InstanceStateName, states []types.InstanceStateName) bool {
+	for _, candidate := range states {
+		if candidate == state {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+AssertEC2InstanceNotPubliclyReachable asserts that an instance cannot be reached from the internet. The test fails if the instance
+or any of its network interfaces has a public IPv4 address or an IPv6 address, if an Elastic IP address is associated with it, or
+if any of the subnets it has a network interface in routes to an internet gateway. Every reason is reported at once.
+
+# Examples
+
+	AssertEC2InstanceNotPubliclyReachable(t, ctx, client, "i-0123456789abcdef0")
+*/
+func AssertEC2InstanceNotPubliclyReachable(t *testing.T, ctx context.Context, client EC2Client, instanceID string) {
+	instance, err := getEC2InstanceByInstanceIDE(ctx, client, instanceID)
+	require.NoError(t, err)
+
+	var violations []string
+	var subnetIDs []string
+	if instance.PublicIpAddress != nil {
+		violations = append(violations, fmt.Sprintf("has public IPv4 address %s", *instance.PublicIpAddress))
+	}
+	if instance.SubnetId != nil {
+		subnetIDs = appendUnique(subnetIDs, *instance.SubnetId)
+	}
+	for _, networkInterface := range instance.NetworkInterfaces {
+		if networkInterface.Association != nil && networkInterface.Association.PublicIp != nil &&
+			(instance.PublicIpAddress == nil || *networkInterface.Association.PublicIp != *instance.PublicIpAddress) {
+			violations = append(violations, fmt.Sprintf("network interface %s has public IPv4 address %s", *networkInterface.NetworkInterfaceId, *networkInterface.Association.PublicIp))
+		}
+		for _, ipv6Address := range networkInterface.Ipv6Addresses {
+			if ipv6Address.Ipv6Address != nil {
+				violations = append(violations, fmt.Sprintf("network interface %s has IPv6 address %s", *networkInterface.NetworkInterfaceId, *ipv6Address.Ipv6Address))
+			}
+		}
+		if networkInterface.SubnetId != nil {
+			subnetIDs = appendUnique(subnetIDs, *networkInterface.SubnetId)
+		}
+	}
+
+	addressesOutput, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+		Filters: CreateFiltersFromMap(map[string][]string{instanceIDFilterName: {instanceID}}),
+	})
+	require.NoError(t, err)
+	for _, address := range addressesOutput.Addresses {
+		if address.PublicIp != nil {
+			violations = append(violations, fmt.Sprintf("has Elastic IP address %s", *address.PublicIp))
+		}
+	}
+
+	for _, subnetID := range subnetIDs {
+		routeTable, err := getVPCSubnetRouteTableE(ctx, client, subnetID)
+		require.NoError(t, err)
+		for _, route := range getVPCInternetGatewayRoutes(routeTable) {
+			violations = append(violations, fmt.Sprintf("subnet %s has internet gateway route %s", subnetID, route))
+		}
+	}
+
+	assert.Empty(t, violations, "Instance '%s' is publicly reachable:\n%s", instanceID, strings.Join(violations, "\n"))
+}
+
+// EC2ExpectedInstanceAttributes describes the expected attributes of an EC2 instance, for use with the AssertEC2InstanceAttributes
+// method. Any attribute that is left unset is not checked.
+type EC2ExpectedInstanceAttributes struct {
+	// The instance types that the instance may have.
+	InstanceTypes []types.InstanceType
+	// The ID of the AMI that the instance was launched from.
+	ImageID string
+	// A regular expression that the name of the AMI that the instance was launched from must match.
+	ImageNamePattern string
+	// The ARN of the IAM instance profile associated with the instance.
+	IAMInstanceProfileARN string
+	// Whether the instance can be terminated through the API.
+	TerminationProtection *bool
+	// Whether the instance can be stopped through the API.
+	StopProtection *bool
+	// Whether detailed monitoring is enabled.
+	DetailedMonitoring *bool
+	// Whether the instance is EBS-optimized.
+	EBSOptimized *bool
+	// The tenancy of the instance, e.g. dedicated.
+	Tenancy types.Tenancy
+	// The name of the key pair that the instance was launched with.
+	KeyName string
+}
+
+/*
+AssertEC2InstanceAttributes asserts that an instance has the expected attributes. Termination and stop protection are read with the
+DescribeInstanceAttribute API and the AMI name with the DescribeImages API, and these calls are only made if the corresponding
+attributes are set.
+
+# Examples
+
+Assert that an instance is a protected, monitored m6i instance launched from a golden AMI.
+
+	AssertEC2InstanceAttributes(t, ctx, client, "i-0123456789abcdef0", EC2ExpectedInstanceAttributes{
+		InstanceTypes:         []types.InstanceType{types.InstanceTypeM6iLarge, types.InstanceTypeM6iXlarge},
+		ImageNamePattern:      `^golden-al2023-`,
+		TerminationProtection: aws.Bool(true),
+		DetailedMonitoring:    aws.Bool(true),
+	})
+*/
+func AssertEC2InstanceAttributes(t *testing.T, ctx context.Context, client EC2Client, instanceID string, expected EC2ExpectedInstanceAttributes) {
+	instance, err := getEC2InstanceByInstanceIDE(ctx, client, instanceID)
+	require.NoError(t, err)
+
+	if len(expected.InstanceTypes) > 0 {
+		assert.Contains(t, expected.InstanceTypes, instance.InstanceType, "Instance '%s' does not have an allowed instance type.", instanceID)
+	}
+	if expected.ImageID != "" {
+		assert.Equal(t, &expected.ImageID, instance.ImageId, "Instance '%s' was not launched from the right AMI.", instanceID)
+	}
+	if expected.ImageNamePattern != "" {
+		re, err := regexp.Compile(expected.ImageNamePattern)
+		require.NoError(t, err)
+		require.NotNil(t, instance.ImageId, "Instance '%s' does not have an AMI ID.", instanceID)
+		image, err := getEC2ImageByImageIDE(ctx, client, *instance.ImageId)
+		require.NoError(t, err)
+		imageName := ""
+		if image.Name != nil {
+			imageName = *image.Name
+		}
+		assert.Regexp(t, re, imageName, "The AMI of instance '%s' does not have a matching name.", instanceID)
+	}
+	if expected.IAMInstanceProfileARN != "" {
+		var profileARN *string
+		if instance.IamInstanceProfile != nil {
+			profileARN = instance.IamInstanceProfile.Arn
+		}
+		assert.Equal(t, &expected.IAMInstanceProfileARN, profileARN, "Instance '%s' does not have the right IAM instance profile.", instanceID)
+	}
+	if expected.TerminationProtection != nil {
+		enabled, err := getEC2InstanceBooleanAttributeE(ctx, client, instanceID, types.InstanceAttributeNameDisableApiTermination)
+		require.NoError(t, err)
+		assert.Equal(t, *expected.TerminationProtection, enabled, "Instance '%s' does not have the right termination protection setting.", instanceID)
+	}
+	if expected.StopProtection != nil {
+		enabled, err := getEC2InstanceBooleanAttributeE(ctx, client, instanceID, types.InstanceAttributeNameDisableApiStop)
+		require.NoError(t, err)
+		assert.Equal(t, *expected.StopProtection, enabled, "Instance '%s' does not have the right stop protection setting.", instanceID)
+	}
+	if expected.DetailedMonitoring != nil {
+		enabled := instance.Monitoring != nil && instance.Monitoring.State == types.MonitoringStateEnabled
+		assert.Equal(t, *expected.DetailedMonitoring, enabled, "Instance '%s' does not have the right detailed monitoring setting.", instanceID)
+	}
+	if expected.EBSOptimized != nil {
+		enabled := instance.EbsOptimized != nil && *instance.EbsOptimized
+		assert.Equal(t, *expected.EBSOptimized, enabled, "Instance '%s' does not have the right EBS optimization setting.", instanceID)
+	}
+	if expected.Tenancy != "" {
+		var tenancy types.Tenancy
+		if instance.Placement != nil {
+			tenancy = instance.Placement.Tenancy
+		}
+		assert.Equal(t, expected.Tenancy, tenancy, "Instance '%s' does not have the right tenancy.", instanceID)
+	}
+	if expected.KeyName != "" {
+		assert.Equal(t, &expected.KeyName, instance.KeyName, "Instance '%s' was not launched with the right key pair.", instanceID)
+	}
+}
+
+// getEC2InstanceBooleanAttributeE returns the value of one of the boolean attributes of an instance, i.e. disableApiTermination or
+// disableApiStop.
+func getEC2InstanceBooleanAttributeE(ctx context.Context, client EC2Client, instanceID string, attribute types.InstanceAttributeName) (bool, error) {
+	input := &ec2.DescribeInstanceAttributeInput{
+		Attribute:  attribute,
+		InstanceId: &instanceID,
+	}
+	output, err := client.DescribeInstanceAttribute(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	var value *types.AttributeBooleanValue
+	switch attribute {
+	case types.InstanceAttributeNameDisableApiTermination:
+		value = output.DisableApiTermination
+	case types.InstanceAttributeNameDisableApiStop:
+		value = output.DisableApiStop
+	default:
+		return false, fmt.Errorf("instance attribute '%s' is not a boolean attribute", attribute)
+	}
+	return value != nil && value.Value != nil && *value.Value, nil
+}
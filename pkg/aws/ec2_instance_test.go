@@ -0,0 +1,353 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEC2InstanceWithMetadataOptions(instanceID string, httpTokens types.HttpTokensState, hopLimit int32) types.Instance {
+	return types.Instance{
+		InstanceId: aws.String(instanceID),
+		MetadataOptions: &types.InstanceMetadataOptionsResponse{
+			HttpTokens:              httpTokens,
+			HttpPutResponseHopLimit: aws.Int32(hopLimit),
+			HttpEndpoint:            types.InstanceMetadataEndpointStateEnabled,
+			InstanceMetadataTags:    types.InstanceMetadataTagsStateDisabled,
+		},
+	}
+}
+
+func TestAssertEC2InstanceMetadataOptions_InstanceIDMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{
+					Instances: []types.Instance{newTestEC2InstanceWithMetadataOptions("i-123456", types.HttpTokensStateRequired, 1)},
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceMetadataOptions(fakeTest, ctx, clientMock,
+		WithEC2MetadataInstanceID("i-123456"),
+		WithEC2MetadataHTTPTokensRequired(),
+		WithEC2MetadataMaximumHopLimit(1),
+		WithEC2MetadataHTTPEndpointEnabled(true),
+		WithEC2MetadataInstanceTagsEnabled(false),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceMetadataOptions_InstanceTagsNoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{Filters: CreateFiltersFromMap(map[string][]string{"tag:service": {"api"}})}).
+		Times(1).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{
+					Instances: []types.Instance{
+						newTestEC2InstanceWithMetadataOptions("i-123456", types.HttpTokensStateRequired, 1),
+						newTestEC2InstanceWithMetadataOptions("i-654321", types.HttpTokensStateOptional, 1),
+					},
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceMetadataOptions(fakeTest, ctx, clientMock,
+		WithEC2MetadataInstanceTags(map[string][]string{"service": {"api"}}),
+		WithEC2MetadataHTTPTokensRequired(),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceMetadataOptions_InstanceTagsIgnoresStoppedInstances(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	runningInstance := newTestEC2InstanceWithMetadataOptions("i-123456", types.HttpTokensStateRequired, 1)
+	runningInstance.State = &types.InstanceState{Name: types.InstanceStateNameRunning}
+	stoppedInstance := newTestEC2InstanceWithMetadataOptions("i-654321", types.HttpTokensStateOptional, 1)
+	stoppedInstance.State = &types.InstanceState{Name: types.InstanceStateNameStopped}
+	output := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{runningInstance, stoppedInstance},
+			},
+		},
+	}
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{Filters: CreateFiltersFromMap(map[string][]string{"tag:service": {"api"}})}).
+		Times(1).
+		Return(output, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceMetadataOptions(fakeTest, ctx, clientMock,
+		WithEC2MetadataInstanceTags(map[string][]string{"service": {"api"}}),
+		WithEC2MetadataHTTPTokensRequired(),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceMetadataOptions_HopLimitTooHigh(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{
+					Instances: []types.Instance{newTestEC2InstanceWithMetadataOptions("i-123456", types.HttpTokensStateRequired, 2)},
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceMetadataOptions(fakeTest, ctx, clientMock,
+		WithEC2MetadataInstanceID("i-123456"),
+		WithEC2MetadataMaximumHopLimit(1),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func newTestEC2PublicReachabilityClient(t *testing.T, instance types.Instance, addresses []types.Address, routes ...types.Route) *mock.MockEC2Client {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	instancesOutput := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{instance},
+			},
+		},
+	}
+	addressesInput := &ec2.DescribeAddressesInput{
+		Filters: CreateFiltersFromMap(map[string][]string{"instance-id": {"i-123456"}}),
+	}
+	routeTablesInput := &ec2.DescribeRouteTablesInput{
+		Filters: CreateFiltersFromMap(map[string][]string{"association.subnet-id": {"subnet-123456"}}),
+	}
+	routeTablesOutput := &ec2.DescribeRouteTablesOutput{
+		RouteTables: []types.RouteTable{newTestVPCRouteTable(routes...)},
+	}
+	clientMock.EXPECT().DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-123456"}}).Times(1).Return(instancesOutput, nil)
+	clientMock.EXPECT().DescribeAddresses(ctx, addressesInput).Times(1).Return(&ec2.DescribeAddressesOutput{Addresses: addresses}, nil)
+	clientMock.EXPECT().DescribeRouteTables(ctx, routeTablesInput).Times(1).Return(routeTablesOutput, nil)
+	return clientMock
+}
+
+func newTestEC2PrivateInstance() types.Instance {
+	return types.Instance{
+		InstanceId: aws.String("i-123456"),
+		SubnetId:   aws.String("subnet-123456"),
+		NetworkInterfaces: []types.InstanceNetworkInterface{
+			{
+				NetworkInterfaceId: aws.String("eni-123456"),
+				SubnetId:           aws.String("subnet-123456"),
+			},
+		},
+	}
+}
+
+func TestAssertEC2InstanceNotPubliclyReachable_Private(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2PublicReachabilityClient(t, newTestEC2PrivateInstance(), nil, types.Route{
+		DestinationCidrBlock: aws.String("0.0.0.0/0"),
+		NatGatewayId:         aws.String("nat-123456"),
+		State:                types.RouteStateActive,
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceNotPubliclyReachable(fakeTest, context.Background(), clientMock, "i-123456")
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceNotPubliclyReachable_ElasticIP(t *testing.T) {
+	// Setup
+	addresses := []types.Address{
+		{
+			InstanceId: aws.String("i-123456"),
+			PublicIp:   aws.String("203.0.113.10"),
+		},
+	}
+	clientMock := newTestEC2PublicReachabilityClient(t, newTestEC2PrivateInstance(), addresses)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceNotPubliclyReachable(fakeTest, context.Background(), clientMock, "i-123456")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceNotPubliclyReachable_IPv6(t *testing.T) {
+	// Setup
+	instance := newTestEC2PrivateInstance()
+	instance.NetworkInterfaces[0].Ipv6Addresses = []types.InstanceIpv6Address{
+		{Ipv6Address: aws.String("2600:1f18::10")},
+	}
+	clientMock := newTestEC2PublicReachabilityClient(t, instance, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceNotPubliclyReachable(fakeTest, context.Background(), clientMock, "i-123456")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceNotPubliclyReachable_InternetGatewayRoute(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2PublicReachabilityClient(t, newTestEC2PrivateInstance(), nil, types.Route{
+		DestinationCidrBlock: aws.String("0.0.0.0/0"),
+		GatewayId:            aws.String("igw-123456"),
+		State:                types.RouteStateActive,
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceNotPubliclyReachable(fakeTest, context.Background(), clientMock, "i-123456")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func newTestEC2InstanceWithAttributes() types.Instance {
+	return types.Instance{
+		InstanceId:         aws.String("i-123456"),
+		InstanceType:       types.InstanceTypeM6iLarge,
+		ImageId:            aws.String("ami-123456"),
+		IamInstanceProfile: &types.IamInstanceProfile{Arn: aws.String("arn:aws:iam::123456789012:instance-profile/api")},
+		Monitoring:         &types.Monitoring{State: types.MonitoringStateEnabled},
+		EbsOptimized:       aws.Bool(true),
+		Placement:          &types.Placement{Tenancy: types.TenancyDefault},
+		KeyName:            aws.String("ops"),
+	}
+}
+
+func TestAssertEC2InstanceAttributes_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{
+					Instances: []types.Instance{newTestEC2InstanceWithAttributes()},
+				},
+			},
+		}, nil)
+	terminationInput := &ec2.DescribeInstanceAttributeInput{
+		Attribute:  types.InstanceAttributeNameDisableApiTermination,
+		InstanceId: aws.String("i-123456"),
+	}
+	stopInput := &ec2.DescribeInstanceAttributeInput{
+		Attribute:  types.InstanceAttributeNameDisableApiStop,
+		InstanceId: aws.String("i-123456"),
+	}
+	imagesOutput := &ec2.DescribeImagesOutput{
+		Images: []types.Image{
+			{
+				ImageId: aws.String("ami-123456"),
+				Name:    aws.String("golden-al2023-20240101"),
+			},
+		},
+	}
+	clientMock.EXPECT().
+		DescribeInstanceAttribute(ctx, terminationInput).
+		Times(1).
+		Return(&ec2.DescribeInstanceAttributeOutput{DisableApiTermination: &types.AttributeBooleanValue{Value: aws.Bool(true)}}, nil)
+	clientMock.EXPECT().
+		DescribeInstanceAttribute(ctx, stopInput).
+		Times(1).
+		Return(&ec2.DescribeInstanceAttributeOutput{DisableApiStop: &types.AttributeBooleanValue{Value: aws.Bool(false)}}, nil)
+	clientMock.EXPECT().DescribeImages(ctx, &ec2.DescribeImagesInput{ImageIds: []string{"ami-123456"}}).Times(1).Return(imagesOutput, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceAttributes(fakeTest, ctx, clientMock, "i-123456", EC2ExpectedInstanceAttributes{
+		InstanceTypes:         []types.InstanceType{types.InstanceTypeM6iLarge, types.InstanceTypeM6iXlarge},
+		ImageID:               "ami-123456",
+		ImageNamePattern:      `^golden-al2023-`,
+		IAMInstanceProfileARN: "arn:aws:iam::123456789012:instance-profile/api",
+		TerminationProtection: aws.Bool(true),
+		StopProtection:        aws.Bool(false),
+		DetailedMonitoring:    aws.Bool(true),
+		EBSOptimized:          aws.Bool(true),
+		Tenancy:               types.TenancyDefault,
+		KeyName:               "ops",
+	})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceAttributes_NoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{
+					Instances: []types.Instance{newTestEC2InstanceWithAttributes()},
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceAttributes(fakeTest, ctx, clientMock, "i-123456", EC2ExpectedInstanceAttributes{
+		InstanceTypes:      []types.InstanceType{types.InstanceTypeT3Micro},
+		DetailedMonitoring: aws.Bool(false),
+		Tenancy:            types.TenancyDedicated,
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
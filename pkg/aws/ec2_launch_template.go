@@ -0,0 +1,207 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// EC2LaunchTemplateDefaultVersion selects the default version of a launch template.
+	EC2LaunchTemplateDefaultVersion string = "$Default"
+	// EC2LaunchTemplateLatestVersion selects the latest version of a launch template.
+	EC2LaunchTemplateLatestVersion string = "$Latest"
+)
+
+// EC2ExpectedLaunchTemplateVersion describes the expected contents of a launch template version, for use with the
+// AssertEC2LaunchTemplateVersion method. Any attribute that is left unset is not checked.
+type EC2ExpectedLaunchTemplateVersion struct {
+	// The instance type.
+	InstanceType types.InstanceType
+	// Whether IMDSv2 session tokens are required.
+	HTTPTokensRequired bool
+	// The maximum allowed metadata response hop limit.
+	MaximumHopLimit *int32
+	// Whether the instance metadata endpoint is enabled.
+	HTTPEndpointEnabled *bool
+	// The expected block device mappings, keyed by device name. As with the AssertEC2InstanceVolumes method, the test also fails if
+	// the version has a device that is not in the table.
+	BlockDeviceMappings map[string]EC2ExpectedVolume
+	// The IDs of the security groups, which must match exactly. Groups set on network interfaces are included.
+	SecurityGroupIDs []string
+	// The hex-encoded SHA-256 checksum of the decoded user data.
+	UserDataSHA256 string
+	// Tags that must be applied to resources on launch, keyed by resource type and then by tag key.
+	Tags map[types.ResourceType]map[string]string
+}
+
+/*
+AssertEC2LaunchTemplateVersion asserts that a version of a launch template has the expected contents. The version may be a version
+number or one of EC2LaunchTemplateDefaultVersion or EC2LaunchTemplateLatestVersion.
+
+# Examples
+
+Assert that the default version of a launch template requires IMDSv2, has an encrypted root volume and runs the expected user data.
+
+	AssertEC2LaunchTemplateVersion(t, ctx, client, "lt-0123456789abcdef0", EC2LaunchTemplateDefaultVersion, EC2ExpectedLaunchTemplateVersion{
+		InstanceType:       types.InstanceTypeM6iLarge,
+		HTTPTokensRequired: true,
+		BlockDeviceMappings: map[string]EC2ExpectedVolume{
+			"/dev/xvda": {Encrypted: aws.Bool(true)},
+		},
+		UserDataSHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Tags: map[types.ResourceType]map[string]string{
+			types.ResourceTypeInstance: {"service": "api"},
+		},
+	})
+*/
+func AssertEC2LaunchTemplateVersion(t *testing.T, ctx context.Context, client EC2Client, launchTemplateID string, version string, expected EC2ExpectedLaunchTemplateVersion) {
+	templateVersion, err := getEC2LaunchTemplateVersionE(ctx, client, launchTemplateID, version)
+	require.NoError(t, err)
+	require.NotNil(t, templateVersion.LaunchTemplateData, "Launch template '%s' version '%s' does not have any data.", launchTemplateID, version)
+	data := templateVersion.LaunchTemplateData
+	name := fmt.Sprintf("%s version %s", launchTemplateID, version)
+	if templateVersion.VersionNumber != nil {
+		name = fmt.Sprintf("%s version %d", launchTemplateID, *templateVersion.VersionNumber)
+	}
+
+	if expected.InstanceType != "" {
+		assert.Equal(t, expected.InstanceType, data.InstanceType, "Launch template '%s' does not have the right instance type.", name)
+	}
+
+	if expected.HTTPTokensRequired || expected.MaximumHopLimit != nil || expected.HTTPEndpointEnabled != nil {
+		metadataOptions := data.MetadataOptions
+		if metadataOptions == nil {
+			metadataOptions = &types.LaunchTemplateInstanceMetadataOptions{}
+		}
+		if expected.HTTPTokensRequired {
+			assert.Equal(t, types.LaunchTemplateHttpTokensStateRequired, metadataOptions.HttpTokens, "Launch template '%s' does not require IMDSv2 session tokens.", name)
+		}
+		if expected.MaximumHopLimit != nil {
+			if assert.NotNil(t, metadataOptions.HttpPutResponseHopLimit, "Launch template '%s' does not have a metadata hop limit.", name) {
+				assert.LessOrEqual(t, *metadataOptions.HttpPutResponseHopLimit, *expected.MaximumHopLimit, "Launch template '%s' has a metadata hop limit that is too high.", name)
+			}
+		}
+		if expected.HTTPEndpointEnabled != nil {
+			// The metadata endpoint is enabled unless it is explicitly disabled.
+			enabled := metadataOptions.HttpEndpoint != types.LaunchTemplateInstanceMetadataEndpointStateDisabled
+			assert.Equal(t, *expected.HTTPEndpointEnabled, enabled, "Launch template '%s' has an unexpected metadata endpoint state.", name)
+		}
+	}
+
+	if expected.BlockDeviceMappings != nil {
+		assertEC2LaunchTemplateBlockDeviceMappings(t, name, data.BlockDeviceMappings, expected.BlockDeviceMappings)
+	}
+
+	if expected.SecurityGroupIDs != nil {
+		var groupIDs []string
+		for _, groupID := range data.SecurityGroupIds {
+			groupIDs = appendUnique(groupIDs, groupID)
+		}
+		for _, networkInterface := range data.NetworkInterfaces {
+			for _, groupID := range networkInterface.Groups {
+				groupIDs = appendUnique(groupIDs, groupID)
+			}
+		}
+		assert.ElementsMatch(t, expected.SecurityGroupIDs, groupIDs, "Launch template '%s' does not have the right security groups.", name)
+	}
+
+	if expected.UserDataSHA256 != "" {
+		userDataSHA256 := ""
+		if data.UserData != nil {
+			userData, err := base64.StdEncoding.DecodeString(*data.UserData)
+			require.NoError(t, err)
+			checksum := sha256.Sum256(userData)
+			userDataSHA256 = hex.EncodeToString(checksum[:])
+		}
+		assert.Equal(t, expected.UserDataSHA256, userDataSHA256, "Launch template '%s' does not have the right user data.", name)
+	}
+
+	for resourceType, expectedTags := range expected.Tags {
+		tags := make(map[string]string)
+		for _, tagSpecification := range data.TagSpecifications {
+			if tagSpecification.ResourceType != resourceType {
+				continue
+			}
+			for _, tag := range tagSpecification.Tags {
+				if tag.Key != nil && tag.Value != nil {
+					tags[*tag.Key] = *tag.Value
+				}
+			}
+		}
+		for key, value := range expectedTags {
+			actual, ok := tags[key]
+			if assert.True(t, ok, "Launch template '%s' does not apply tag '%s' to resources of type '%s'.", name, key, resourceType) {
+				assert.Equal(t, value, actual, "Launch template '%s' applies the wrong value of tag '%s' to resources of type '%s'.", name, key, resourceType)
+			}
+		}
+	}
+}
+
+// assertEC2LaunchTemplateBlockDeviceMappings asserts that the block device mappings of a launch template version match a table
+// of expected volumes, keyed by device name.
+func assertEC2LaunchTemplateBlockDeviceMappings(t *testing.T, name string, mappings []types.LaunchTemplateBlockDeviceMapping, expectedVolumes map[string]EC2ExpectedVolume) {
+	devices := make(map[string]types.LaunchTemplateEbsBlockDevice)
+	for _, mapping := range mappings {
+		if mapping.DeviceName == nil || mapping.Ebs == nil {
+			continue
+		}
+		devices[*mapping.DeviceName] = *mapping.Ebs
+		_, ok := expectedVolumes[*mapping.DeviceName]
+		assert.True(t, ok, "Launch template '%s' has unexpected device '%s'.", name, *mapping.DeviceName)
+	}
+
+	for deviceName, expected := range expectedVolumes {
+		device, ok := devices[deviceName]
+		if !assert.True(t, ok, "Device '%s' was not found in launch template '%s'.", deviceName, name) {
+			continue
+		}
+		if expected.VolumeType != "" {
+			assert.Equal(t, expected.VolumeType, device.VolumeType, "Device '%s' in launch template '%s' does not have the right volume type.", deviceName, name)
+		}
+		if expected.Size != nil {
+			assert.Equal(t, expected.Size, device.VolumeSize, "Device '%s' in launch template '%s' does not have the right size.", deviceName, name)
+		}
+		if expected.IOPS != nil {
+			assert.Equal(t, expected.IOPS, device.Iops, "Device '%s' in launch template '%s' does not have the right IOPS value.", deviceName, name)
+		}
+		if expected.Throughput != nil {
+			assert.Equal(t, expected.Throughput, device.Throughput, "Device '%s' in launch template '%s' does not have the right throughput.", deviceName, name)
+		}
+		if expected.Encrypted != nil {
+			assert.Equal(t, expected.Encrypted, device.Encrypted, "Device '%s' in launch template '%s' does not have the right encryption setting.", deviceName, name)
+		}
+		if expected.KMSKeyID != "" {
+			assert.Equal(t, &expected.KMSKeyID, device.KmsKeyId, "Device '%s' in launch template '%s' does not use the correct KMS Key ID.", deviceName, name)
+		}
+		if expected.DeleteOnTermination != nil {
+			assert.Equal(t, expected.DeleteOnTermination, device.DeleteOnTermination, "Device '%s' in launch template '%s' does not have the right delete on termination setting.", deviceName, name)
+		}
+	}
+}
+
+// getEC2LaunchTemplateVersionE returns a single version of a launch template.
+func getEC2LaunchTemplateVersionE(ctx context.Context, client EC2Client, launchTemplateID string, version string) (types.LaunchTemplateVersion, error) {
+	input := &ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: &launchTemplateID,
+		Versions:         []string{version},
+	}
+	output, err := client.DescribeLaunchTemplateVersions(ctx, input)
+	if err != nil {
+		return types.LaunchTemplateVersion{}, err
+	}
+	if len(output.LaunchTemplateVersions) == 0 {
+		return types.LaunchTemplateVersion{}, fmt.Errorf("version '%s' of launch template '%s' was not found", version, launchTemplateID)
+	}
+	return output.LaunchTemplateVersions[0], nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEC2LaunchTemplateClient(t *testing.T, version string) *mock.MockEC2Client {
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	input := &ec2.DescribeLaunchTemplateVersionsInput{
+		LaunchTemplateId: aws.String("lt-123456"),
+		Versions:         []string{version},
+	}
+	output := &ec2.DescribeLaunchTemplateVersionsOutput{
+		LaunchTemplateVersions: []types.LaunchTemplateVersion{
+			{
+				LaunchTemplateId: aws.String("lt-123456"),
+				VersionNumber:    aws.Int64(3),
+				LaunchTemplateData: &types.ResponseLaunchTemplateData{
+					InstanceType: types.InstanceTypeM6iLarge,
+					MetadataOptions: &types.LaunchTemplateInstanceMetadataOptions{
+						HttpTokens:              types.LaunchTemplateHttpTokensStateRequired,
+						HttpPutResponseHopLimit: aws.Int32(1),
+					},
+					BlockDeviceMappings: []types.LaunchTemplateBlockDeviceMapping{
+						{
+							DeviceName: aws.String("/dev/xvda"),
+							Ebs: &types.LaunchTemplateEbsBlockDevice{
+								VolumeType: types.VolumeTypeGp3,
+								VolumeSize: aws.Int32(20),
+								Encrypted:  aws.Bool(true),
+							},
+						},
+					},
+					SecurityGroupIds: []string{"sg-123456"},
+					NetworkInterfaces: []types.LaunchTemplateInstanceNetworkInterfaceSpecification{
+						{Groups: []string{"sg-654321"}},
+					},
+					UserData: aws.String("IyEvYmluL2Jhc2gKZWNobyBoZWxsbwo="),
+					TagSpecifications: []types.LaunchTemplateTagSpecification{
+						{
+							ResourceType: types.ResourceTypeInstance,
+							Tags: []types.Tag{
+								{Key: aws.String("service"), Value: aws.String("api")},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeLaunchTemplateVersions(context.Background(), input).Times(1).Return(output, nil)
+	return clientMock
+}
+
+func TestAssertEC2LaunchTemplateVersion_Match(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2LaunchTemplateClient(t, EC2LaunchTemplateDefaultVersion)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2LaunchTemplateVersion(fakeTest, context.Background(), clientMock, "lt-123456", EC2LaunchTemplateDefaultVersion, EC2ExpectedLaunchTemplateVersion{
+		InstanceType:        types.InstanceTypeM6iLarge,
+		HTTPTokensRequired:  true,
+		MaximumHopLimit:     aws.Int32(1),
+		HTTPEndpointEnabled: aws.Bool(true),
+		BlockDeviceMappings: map[string]EC2ExpectedVolume{
+			"/dev/xvda": {
+				VolumeType: types.VolumeTypeGp3,
+				Size:       aws.Int32(20),
+				Encrypted:  aws.Bool(true),
+			},
+		},
+		SecurityGroupIDs: []string{"sg-654321", "sg-123456"},
+		UserDataSHA256:   "f590776b449af73e55cb368f45ce28400a19d0c68cdb34485fdfc0602b6c2437",
+		Tags: map[types.ResourceType]map[string]string{
+			types.ResourceTypeInstance: {"service": "api"},
+		},
+	})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2LaunchTemplateVersion_NoMatch(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2LaunchTemplateClient(t, EC2LaunchTemplateLatestVersion)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2LaunchTemplateVersion(fakeTest, context.Background(), clientMock, "lt-123456", EC2LaunchTemplateLatestVersion, EC2ExpectedLaunchTemplateVersion{
+		SecurityGroupIDs: []string{"sg-123456"},
+		UserDataSHA256:   "0000000000000000000000000000000000000000000000000000000000000000",
+		Tags: map[types.ResourceType]map[string]string{
+			types.ResourceTypeVolume: {"service": "api"},
+		},
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2LaunchTemplateVersion_UnexpectedDevice(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2LaunchTemplateClient(t, "3")
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2LaunchTemplateVersion(fakeTest, context.Background(), clientMock, "lt-123456", "3", EC2ExpectedLaunchTemplateVersion{
+		BlockDeviceMappings: map[string]EC2ExpectedVolume{},
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
@@ -0,0 +1,302 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	networkACLSubnetIDFilterName string = "association.subnet-id"
+	ec2DefaultEphemeralPortFrom  int32  = 1024
+	ec2DefaultEphemeralPortTo    int32  = 65535
+	ec2MaximumPort               int32  = 65535
+)
+
+// EC2NetworkACLDirection is the direction of a flow relative to the subnet that a network ACL is associated with.
+type EC2NetworkACLDirection string
+
+const (
+	// EC2NetworkACLInbound describes a flow that is initiated from outside the subnet to a port inside it.
+	EC2NetworkACLInbound EC2NetworkACLDirection = "inbound"
+	// EC2NetworkACLOutbound describes a flow that is initiated from inside the subnet to a port outside it.
+	EC2NetworkACLOutbound EC2NetworkACLDirection = "outbound"
+)
+
+// AssertEC2NetworkACLOptions is a struct used for functional options for the AssertEC2NetworkACLAllows and AssertEC2NetworkACLDenies
+// methods.
+type AssertEC2NetworkACLOptions struct {
+	// The direction of the flow. Defaults to inbound.
+	Direction EC2NetworkACLDirection
+	// The protocol of the flow, either as a name (e.g. "tcp") or number (e.g. "6"). Defaults to "tcp".
+	Protocol string
+	// The destination port of the flow. Required for TCP and UDP, and ignored for other protocols.
+	Port *int32
+	// The IPv4 or IPv6 CIDR block at the other end of the flow, i.e. the source of an inbound flow or the destination of an
+	// outbound flow.
+	CIDR string
+	// The first port of the ephemeral range that return traffic is sent to. Defaults to 1024.
+	EphemeralPortFrom int32
+	// The last port of the ephemeral range that return traffic is sent to. Defaults to 65535.
+	EphemeralPortTo int32
+}
+
+// AssertEC2NetworkACLOptsFunc is a type used for functional options for the AssertEC2NetworkACLAllows and AssertEC2NetworkACLDenies
+// methods.
+type AssertEC2NetworkACLOptsFunc func(*AssertEC2NetworkACLOptions) error
+
+// WithEC2NetworkACLDirection sets the direction of the flow that is evaluated.
+func WithEC2NetworkACLDirection(direction EC2NetworkACLDirection) AssertEC2NetworkACLOptsFunc {
+	return func(opts *AssertEC2NetworkACLOptions) error {
+		if direction != EC2NetworkACLInbound && direction != EC2NetworkACLOutbound {
+			return fmt.Errorf("network ACL direction '%s' is not valid", direction)
+		}
+		opts.Direction = direction
+		return nil
+	}
+}
+
+// WithEC2NetworkACLProtocol sets the protocol of the flow that is evaluated.
+func WithEC2NetworkACLProtocol(protocol string) AssertEC2NetworkACLOptsFunc {
+	return func(opts *AssertEC2NetworkACLOptions) error {
+		opts.Protocol = protocol
+		return nil
+	}
+}
+
+// WithEC2NetworkACLPort sets the destination port of the flow that is evaluated.
+func WithEC2NetworkACLPort(port int32) AssertEC2NetworkACLOptsFunc {
+	return func(opts *AssertEC2NetworkACLOptions) error {
+		opts.Port = &port
+		return nil
+	}
+}
+
+// WithEC2NetworkACLCIDR sets the IPv4 or IPv6 CIDR block at the other end of the flow that is evaluated.
+func WithEC2NetworkACLCIDR(cidr string) AssertEC2NetworkACLOptsFunc {
+	return func(opts *AssertEC2NetworkACLOptions) error {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return err
+		}
+		opts.CIDR = cidr
+		return nil
+	}
+}
+
+// WithEC2NetworkACLEphemeralPortRange sets the range of ports that return traffic is sent to.
+func WithEC2NetworkACLEphemeralPortRange(fromPort int32, toPort int32) AssertEC2NetworkACLOptsFunc {
+	return func(opts *AssertEC2NetworkACLOptions) error {
+		if fromPort > toPort {
+			return fmt.Errorf("port range start %d is greater than port range end %d", fromPort, toPort)
+		}
+		opts.EphemeralPortFrom = fromPort
+		opts.EphemeralPortTo = toPort
+		return nil
+	}
+}
+
+/*
+AssertEC2NetworkACLAllows asserts that the network ACL associated with a subnet allows a flow, described by the passed functional
+options, in both directions. Since network ACLs are stateless, the rules in the direction of the flow must allow the destination
+port, and the rules in the opposite direction must allow the return traffic on every port of the ephemeral range. Rules are
+evaluated in rule number order, and a flow is only allowed if every address of its CIDR block is allowed.
+
+# Examples
+
+Assert that a subnet accepts HTTPS from a load balancer subnet.
+
+	AssertEC2NetworkACLAllows(
+		t,
+		ctx,
+		client,
+		"subnet-0123456789abcdef0",
+		WithEC2NetworkACLPort(443),
+		WithEC2NetworkACLCIDR("10.0.1.0/24"),
+	)
+
+Assert that a subnet can make DNS requests to a resolver outside the VPC.
+
+	AssertEC2NetworkACLAllows(
+		t,
+		ctx,
+		client,
+		"subnet-0123456789abcdef0",
+		WithEC2NetworkACLDirection(EC2NetworkACLOutbound),
+		WithEC2NetworkACLProtocol("udp"),
+		WithEC2NetworkACLPort(53),
+		WithEC2NetworkACLCIDR("192.168.0.2/32"),
+	)
+*/
+func AssertEC2NetworkACLAllows(t *testing.T, ctx context.Context, client EC2Client, subnetID string, optFns ...AssertEC2NetworkACLOptsFunc) {
+	requestAllowed, returnAllowed, description := evaluateEC2NetworkACL(t, ctx, client, subnetID, optFns)
+	assert.True(t, requestAllowed, "Network ACL rules do not allow %s.", description)
+	assert.True(t, returnAllowed, "Network ACL rules do not allow the return traffic of %s.", description)
+}
+
+// AssertEC2NetworkACLDenies asserts that the network ACL associated with a subnet does not allow a flow, described by the passed
+// functional options, in both directions. See AssertEC2NetworkACLAllows for how flows are evaluated.
+func AssertEC2NetworkACLDenies(t *testing.T, ctx context.Context, client EC2Client, subnetID string, optFns ...AssertEC2NetworkACLOptsFunc) {
+	requestAllowed, returnAllowed, description := evaluateEC2NetworkACL(t, ctx, client, subnetID, optFns)
+	assert.False(t, requestAllowed && returnAllowed, "Network ACL rules allow %s and its return traffic.", description)
+}
+
+// evaluateEC2NetworkACL returns whether the network ACL of a subnet allows the flow described by the functional options and its
+// return traffic, along with a description of the flow for use in test failure messages.
+func evaluateEC2NetworkACL(t *testing.T, ctx context.Context, client EC2Client, subnetID string, optFns []AssertEC2NetworkACLOptsFunc) (requestAllowed bool, returnAllowed bool, description string) {
+	opts := &AssertEC2NetworkACLOptions{
+		Direction:         EC2NetworkACLInbound,
+		Protocol:          "tcp",
+		EphemeralPortFrom: ec2DefaultEphemeralPortFrom,
+		EphemeralPortTo:   ec2DefaultEphemeralPortTo,
+	}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+	require.NotEmpty(t, opts.CIDR, "A CIDR must be set by one or more passed functional options.")
+	usesPorts := ec2ProtocolUsesPorts(opts.Protocol)
+	if usesPorts {
+		require.NotNil(t, opts.Port, "A Port must be set by one or more passed functional options.")
+	}
+
+	networkACL, err := getEC2SubnetNetworkACLE(ctx, client, subnetID)
+	require.NoError(t, err)
+
+	requestEgress := opts.Direction == EC2NetworkACLOutbound
+	if usesPorts {
+		requestAllowed = ec2NetworkACLEntriesAllow(networkACL.Entries, requestEgress, opts.Protocol, opts.CIDR, *opts.Port, *opts.Port)
+		returnAllowed = ec2NetworkACLEntriesAllow(networkACL.Entries, !requestEgress, opts.Protocol, opts.CIDR, opts.EphemeralPortFrom, opts.EphemeralPortTo)
+		description = fmt.Sprintf("%s %s traffic for %s on port %d in network ACL '%s' (return ports %d-%d)", opts.Direction, opts.Protocol, opts.CIDR, *opts.Port, *networkACL.NetworkAclId, opts.EphemeralPortFrom, opts.EphemeralPortTo)
+	} else {
+		requestAllowed = ec2NetworkACLEntriesAllow(networkACL.Entries, requestEgress, opts.Protocol, opts.CIDR, 0, 0)
+		returnAllowed = ec2NetworkACLEntriesAllow(networkACL.Entries, !requestEgress, opts.Protocol, opts.CIDR, 0, 0)
+		description = fmt.Sprintf("%s %s traffic for %s in network ACL '%s'", opts.Direction, opts.Protocol, opts.CIDR, *networkACL.NetworkAclId)
+	}
+	return
+}
+
+// getEC2SubnetNetworkACLE returns the network ACL associated with a subnet. Every subnet is associated with exactly one network ACL.
+func getEC2SubnetNetworkACLE(ctx context.Context, client EC2Client, subnetID string) (types.NetworkAcl, error) {
+	input := &ec2.DescribeNetworkAclsInput{
+		Filters: CreateFiltersFromMap(map[string][]string{networkACLSubnetIDFilterName: {subnetID}}),
+	}
+	networkACLs, err := collectPagesE(ctx, ec2.NewDescribeNetworkAclsPaginator(client, input), func(output *ec2.DescribeNetworkAclsOutput) []types.NetworkAcl {
+		return output.NetworkAcls
+	})
+	if err != nil {
+		return types.NetworkAcl{}, err
+	}
+	if len(networkACLs) == 0 {
+		return types.NetworkAcl{}, fmt.Errorf("no network ACL was found for subnet '%s'", subnetID)
+	}
+	return networkACLs[0], nil
+}
+
+// ec2NetworkACLEntriesAllow returns true if the entries of a network ACL in one direction allow traffic of a protocol for every
+// address of a CIDR block and every port in a range. As with AWS, entries are evaluated in rule number order and the first entry
+// that matches a port decides it, and ports that no entry matches are denied. An allow entry only matches if its CIDR block
+// contains the whole of the given block, while a deny entry matches if it overlaps any part of it.
+func ec2NetworkACLEntriesAllow(entries []types.NetworkAclEntry, egress bool, protocol string, cidr string, fromPort int32, toPort int32) bool {
+	var directionEntries []types.NetworkAclEntry
+	for _, entry := range entries {
+		if (entry.Egress != nil && *entry.Egress) == egress && entry.RuleNumber != nil {
+			directionEntries = append(directionEntries, entry)
+		}
+	}
+	sort.Slice(directionEntries, func(i, j int) bool {
+		return *directionEntries[i].RuleNumber < *directionEntries[j].RuleNumber
+	})
+
+	remaining := []ec2PortRange{{from: fromPort, to: toPort}}
+	for _, entry := range directionEntries {
+		if !ec2NetworkACLEntryMatchesProtocol(entry, protocol) {
+			continue
+		}
+		entryCIDR := ""
+		if entry.CidrBlock != nil {
+			entryCIDR = *entry.CidrBlock
+		} else if entry.Ipv6CidrBlock != nil {
+			entryCIDR = *entry.Ipv6CidrBlock
+		}
+		// AWS ignores the port range of entries for all protocols, which may still be returned, e.g. as 0-0.
+		entryPorts := ec2PortRange{from: 0, to: ec2MaximumPort}
+		if ec2ProtocolUsesPorts(protocol) && normalizeEC2Protocol(entry.Protocol) != ec2ProtocolAll &&
+			entry.PortRange != nil && entry.PortRange.From != nil && entry.PortRange.To != nil {
+			entryPorts = ec2PortRange{from: *entry.PortRange.From, to: *entry.PortRange.To}
+		}
+
+		if entry.RuleAction == types.RuleActionDeny {
+			if cidrsOverlap(entryCIDR, cidr) && ec2PortRangesOverlap(remaining, entryPorts) {
+				return false
+			}
+			continue
+		}
+		if !cidrContainsCIDR(entryCIDR, cidr) {
+			continue
+		}
+		remaining = subtractEC2PortRange(remaining, entryPorts)
+		if len(remaining) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ec2NetworkACLEntryMatchesProtocol returns true if a network ACL entry applies to the given protocol.
+func ec2NetworkACLEntryMatchesProtocol(entry types.NetworkAclEntry, protocol string) bool {
+	entryProtocol := normalizeEC2Protocol(entry.Protocol)
+	return entryProtocol == ec2ProtocolAll || entryProtocol == normalizeEC2Protocol(&protocol)
+}
+
+// ec2ProtocolUsesPorts returns true if a protocol has ports, i.e. it is TCP or UDP.
+func ec2ProtocolUsesPorts(protocol string) bool {
+	normalized := normalizeEC2Protocol(&protocol)
+	return normalized == ec2ProtocolNumbers["tcp"] || normalized == ec2ProtocolNumbers["udp"]
+}
+
+// ec2PortRange is an inclusive range of ports.
+type ec2PortRange struct {
+	from int32
+	to   int32
+}
+
+// ec2PortRangesOverlap returns true if a port range overlaps any of a list of ranges.
+func ec2PortRangesOverlap(ranges []ec2PortRange, other ec2PortRange) bool {
+	for _, portRange := range ranges {
+		if portRange.from <= other.to && other.from <= portRange.to {
+			return true
+		}
+	}
+	return false
+}
+
+// subtractEC2PortRange returns the parts of a list of port ranges that are not covered by another range.
+func subtractEC2PortRange(ranges []ec2PortRange, other ec2PortRange) (output []ec2PortRange) {
+	for _, portRange := range ranges {
+		if portRange.to < other.from || other.to < portRange.from {
+			output = append(output, portRange)
+			continue
+		}
+		if portRange.from < other.from {
+			output = append(output, ec2PortRange{from: portRange.from, to: other.from - 1})
+		}
+		if other.to < portRange.to {
+			output = append(output, ec2PortRange{from: other.to + 1, to: portRange.to})
+		}
+	}
+	return
+}
+
+// cidrsOverlap returns true if two CIDR blocks share any address.
+func cidrsOverlap(a string, b string) bool {
+	return cidrContainsCIDR(a, b) || cidrContainsCIDR(b, a)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEC2NetworkACLEntry(ruleNumber int32, egress bool, action types.RuleAction, protocol string, cidr string, fromPort int32, toPort int32) types.NetworkAclEntry {
+	return types.NetworkAclEntry{
+		RuleNumber: aws.Int32(ruleNumber),
+		Egress:     aws.Bool(egress),
+		RuleAction: action,
+		Protocol:   aws.String(protocol),
+		CidrBlock:  aws.String(cidr),
+		PortRange: &types.PortRange{
+			From: aws.Int32(fromPort),
+			To:   aws.Int32(toPort),
+		},
+	}
+}
+
+// newTestEC2NetworkACLEntries returns the entries of a network ACL for a web subnet. It allows HTTPS from the VPC except for one
+// denied subnet, and return traffic to the VPC on all ephemeral ports except 2049.
+func newTestEC2NetworkACLEntries() []types.NetworkAclEntry {
+	return []types.NetworkAclEntry{
+		newTestEC2NetworkACLEntry(100, false, types.RuleActionDeny, "6", "10.0.9.0/24", 0, 65535),
+		newTestEC2NetworkACLEntry(110, false, types.RuleActionAllow, "6", "10.0.0.0/16", 443, 443),
+		newTestEC2NetworkACLEntry(120, false, types.RuleActionAllow, "6", "10.0.0.0/16", 1024, 65535),
+		newTestEC2NetworkACLEntry(100, true, types.RuleActionDeny, "6", "10.0.0.0/16", 2049, 2049),
+		newTestEC2NetworkACLEntry(110, true, types.RuleActionAllow, "6", "10.0.0.0/16", 1024, 65535),
+		newTestEC2NetworkACLEntry(120, true, types.RuleActionAllow, "6", "0.0.0.0/0", 443, 443),
+		newTestEC2NetworkACLEntry(32767, false, types.RuleActionDeny, "-1", "0.0.0.0/0", 0, 0),
+		newTestEC2NetworkACLEntry(32767, true, types.RuleActionDeny, "-1", "0.0.0.0/0", 0, 0),
+	}
+}
+
+func TestEC2NetworkACLEntriesAllow(t *testing.T) {
+	entries := newTestEC2NetworkACLEntries()
+
+	assert.True(t, ec2NetworkACLEntriesAllow(entries, false, "tcp", "10.0.1.0/24", 443, 443))
+	assert.False(t, ec2NetworkACLEntriesAllow(entries, false, "tcp", "10.0.1.0/24", 80, 80))
+	assert.False(t, ec2NetworkACLEntriesAllow(entries, false, "tcp", "10.0.9.10/32", 443, 443))
+	assert.False(t, ec2NetworkACLEntriesAllow(entries, false, "tcp", "10.0.0.0/8", 443, 443))
+	assert.False(t, ec2NetworkACLEntriesAllow(entries, false, "udp", "10.0.1.0/24", 443, 443))
+	assert.True(t, ec2NetworkACLEntriesAllow(entries, true, "tcp", "10.0.1.0/24", 1024, 2048))
+	assert.False(t, ec2NetworkACLEntriesAllow(entries, true, "tcp", "10.0.1.0/24", 1024, 65535))
+	assert.True(t, ec2NetworkACLEntriesAllow(entries, true, "tcp", "192.168.0.0/16", 443, 443))
+}
+
+func TestEC2NetworkACLEntriesAllow_AllProtocolsIgnoresPortRange(t *testing.T) {
+	// Setup
+	denyAll := newTestEC2NetworkACLEntry(90, false, types.RuleActionDeny, "-1", "10.0.9.0/24", 0, 0)
+	denyAll.PortRange = nil
+	entries := []types.NetworkAclEntry{
+		denyAll,
+		newTestEC2NetworkACLEntry(100, false, types.RuleActionAllow, "-1", "10.0.0.0/16", 0, 0),
+	}
+
+	// Assert
+	assert.True(t, ec2NetworkACLEntriesAllow(entries, false, "tcp", "10.0.1.0/24", 443, 443))
+	assert.True(t, ec2NetworkACLEntriesAllow(entries, false, "udp", "10.0.1.0/24", 1024, 65535))
+	assert.False(t, ec2NetworkACLEntriesAllow(entries, false, "tcp", "10.0.9.0/24", 443, 443))
+}
+
+func TestSubtractEC2PortRange(t *testing.T) {
+	ranges := []ec2PortRange{{from: 1024, to: 65535}}
+
+	assert.Equal(t, []ec2PortRange{{from: 1024, to: 2048}, {from: 2050, to: 65535}}, subtractEC2PortRange(ranges, ec2PortRange{from: 2049, to: 2049}))
+	assert.Equal(t, []ec2PortRange{{from: 1024, to: 65535}}, subtractEC2PortRange(ranges, ec2PortRange{from: 0, to: 1023}))
+	assert.Empty(t, subtractEC2PortRange(ranges, ec2PortRange{from: 0, to: 65535}))
+}
+
+func TestAssertEC2NetworkACLAllows_Allowed(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeNetworkAcls(ctx, &ec2.DescribeNetworkAclsInput{
+			Filters: CreateFiltersFromMap(map[string][]string{"association.subnet-id": {"subnet-123456"}}),
+		}).
+		Times(1).
+		Return(&ec2.DescribeNetworkAclsOutput{
+			NetworkAcls: []types.NetworkAcl{
+				{
+					NetworkAclId: aws.String("acl-123456"),
+					Entries:      newTestEC2NetworkACLEntries(),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2NetworkACLAllows(fakeTest, ctx, clientMock, "subnet-123456",
+		WithEC2NetworkACLPort(443),
+		WithEC2NetworkACLCIDR("10.0.1.0/24"),
+		WithEC2NetworkACLEphemeralPortRange(32768, 60999),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2NetworkACLAllows_ReturnTrafficDenied(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeNetworkAcls(ctx, &ec2.DescribeNetworkAclsInput{
+			Filters: CreateFiltersFromMap(map[string][]string{"association.subnet-id": {"subnet-123456"}}),
+		}).
+		Times(1).
+		Return(&ec2.DescribeNetworkAclsOutput{
+			NetworkAcls: []types.NetworkAcl{
+				{
+					NetworkAclId: aws.String("acl-123456"),
+					Entries:      newTestEC2NetworkACLEntries(),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2NetworkACLAllows(fakeTest, ctx, clientMock, "subnet-123456",
+		WithEC2NetworkACLPort(443),
+		WithEC2NetworkACLCIDR("10.0.1.0/24"),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2NetworkACLDenies_Denied(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeNetworkAcls(ctx, &ec2.DescribeNetworkAclsInput{
+			Filters: CreateFiltersFromMap(map[string][]string{"association.subnet-id": {"subnet-123456"}}),
+		}).
+		Times(1).
+		Return(&ec2.DescribeNetworkAclsOutput{
+			NetworkAcls: []types.NetworkAcl{
+				{
+					NetworkAclId: aws.String("acl-123456"),
+					Entries:      newTestEC2NetworkACLEntries(),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2NetworkACLDenies(fakeTest, ctx, clientMock, "subnet-123456",
+		WithEC2NetworkACLDirection(EC2NetworkACLOutbound),
+		WithEC2NetworkACLPort(443),
+		WithEC2NetworkACLCIDR("192.168.0.0/16"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2NetworkACLDenies_Allowed(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeNetworkAcls(ctx, &ec2.DescribeNetworkAclsInput{
+			Filters: CreateFiltersFromMap(map[string][]string{"association.subnet-id": {"subnet-123456"}}),
+		}).
+		Times(1).
+		Return(&ec2.DescribeNetworkAclsOutput{
+			NetworkAcls: []types.NetworkAcl{
+				{
+					NetworkAclId: aws.String("acl-123456"),
+					Entries:      newTestEC2NetworkACLEntries(),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2NetworkACLDenies(fakeTest, ctx, clientMock, "subnet-123456",
+		WithEC2NetworkACLPort(443),
+		WithEC2NetworkACLCIDR("10.0.1.0/24"),
+		WithEC2NetworkACLEphemeralPortRange(32768, 60999),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
@@ -0,0 +1,269 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AssertEC2TrafficOptions is a struct used for functional options for the AssertEC2TrafficAllowed and AssertEC2TrafficDenied methods.
+// Exactly one source and one target must be set.
+type AssertEC2TrafficOptions struct {
+	// The ID of the instance that the traffic originates from.
+	SourceInstanceID string
+	// The ID of the security group that the traffic originates from.
+	SourceGroupID string
+	// The ID of the instance that the traffic is sent to.
+	TargetInstanceID string
+	// The ID of the security group that the traffic is sent to.
+	TargetGroupID string
+	// The protocol of the traffic. Defaults to "tcp".
+	Protocol string
+	// The destination port of the traffic.
+	Port *int32
+}
+
+// AssertEC2TrafficOptsFunc is a type used for functional options for the AssertEC2TrafficAllowed and AssertEC2TrafficDenied methods.
+type AssertEC2TrafficOptsFunc func(*AssertEC2TrafficOptions) error
+
+// WithEC2TrafficSourceInstanceID sets the instance that traffic originates from.
+func WithEC2TrafficSourceInstanceID(instanceID string) AssertEC2TrafficOptsFunc {
+	return func(opts *AssertEC2TrafficOptions) error {
+		opts.SourceInstanceID = instanceID
+		return nil
+	}
+}
+
+// WithEC2TrafficSourceGroupID sets the security group that traffic originates from.
+func WithEC2TrafficSourceGroupID(groupID string) AssertEC2TrafficOptsFunc {
+	return func(opts *AssertEC2TrafficOptions) error {
+		opts.SourceGroupID = groupID
+		return nil
+	}
+}
+
+// WithEC2TrafficTargetInstanceID sets the instance that traffic is sent to.
+func WithEC2TrafficTargetInstanceID(instanceID string) AssertEC2TrafficOptsFunc {
+	return func(opts *AssertEC2TrafficOptions) error {
+		opts.TargetInstanceID = instanceID
+		return nil
+	}
+}
+
+// WithEC2TrafficTargetGroupID sets the security group that traffic is sent to.
+func WithEC2TrafficTargetGroupID(groupID string) AssertEC2TrafficOptsFunc {
+	return func(opts *AssertEC2TrafficOptions) error {
+		opts.TargetGroupID = groupID
+		return nil
+	}
+}
+
+// WithEC2TrafficProtocol sets the protocol of the traffic, either as a name (e.g. "udp") or number (e.g. "17").
+func WithEC2TrafficProtocol(protocol string) AssertEC2TrafficOptsFunc {
+	return func(opts *AssertEC2TrafficOptions) error {
+		opts.Protocol = protocol
+		return nil
+	}
+}
+
+// WithEC2TrafficPort sets the destination port of the traffic.
+func WithEC2TrafficPort(port int32) AssertEC2TrafficOptsFunc {
+	return func(opts *AssertEC2TrafficOptions) error {
+		opts.Port = &port
+		return nil
+	}
+}
+
+// ec2TrafficEndpoint is one end of a traffic flow, described by the security groups and IP addresses it uses.
+type ec2TrafficEndpoint struct {
+	name        string
+	groupIDs    []string
+	ipAddresses []string
+}
+
+/*
+AssertEC2TrafficAllowed asserts that the security groups of a source and a target allow traffic from the source to reach the target
+on a given port. This requires both an egress rule on a source security group and an ingress rule on a target security group, each of
+which must reference a security group of the other end or a CIDR block containing one of its private IP addresses.
+
+Security groups can be used as the source or target in place of an instance, in which case only security group references
+and CIDR blocks covering every address (0.0.0.0/0 or ::/0) are considered, since no IP addresses are known.
+
+Network ACLs and route tables are not evaluated.
+
+# Examples
+
+Assert that an application server can reach a database on port 5432.
+
+	AssertEC2TrafficAllowed(
+		t,
+		ctx,
+		client,
+		WithEC2TrafficSourceInstanceID("i-0123456789abcdef0"),
+		WithEC2TrafficTargetGroupID("sg-0123456789abcdef0"),
+		WithEC2TrafficPort(5432),
+	)
+*/
+func AssertEC2TrafficAllowed(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2TrafficOptsFunc) {
+	egressAllowed, ingressAllowed, description := evaluateEC2Traffic(t, ctx, client, optFns)
+	assert.True(t, egressAllowed, "No egress rule allows %s.", description)
+	assert.True(t, ingressAllowed, "No ingress rule allows %s.", description)
+}
+
+// AssertEC2TrafficDenied asserts that the security groups of a source and a target do not allow traffic from the source to reach the target
+// on a given port, because either the source egress rules or the target ingress rules do not allow it. It accepts the same functional
+// options as the AssertEC2TrafficAllowed method.
+func AssertEC2TrafficDenied(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2TrafficOptsFunc) {
+	egressAllowed, ingressAllowed, description := evaluateEC2Traffic(t, ctx, client, optFns)
+	assert.False(t, egressAllowed && ingressAllowed, "Security group rules allow %s.", description)
+}
+
+// evaluateEC2Traffic returns whether the source egress rules and the target ingress rules allow the traffic described by the
+// functional options, along with a description of the traffic for use in test failure messages.
+func evaluateEC2Traffic(t *testing.T, ctx context.Context, client EC2Client, optFns []AssertEC2TrafficOptsFunc) (egressAllowed bool, ingressAllowed bool, description string) {
+	opts := &AssertEC2TrafficOptions{
+		Protocol: "tcp",
+	}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+	require.NotNil(t, opts.Port, "A Port must be set by one or more passed functional options.")
+	require.True(t, (opts.SourceInstanceID == "") != (opts.SourceGroupID == ""), "Exactly one of a source instance or security group must be set by the passed functional options.")
+	require.True(t, (opts.TargetInstanceID == "") != (opts.TargetGroupID == ""), "Exactly one of a target instance or security group must be set by the passed functional options.")
+
+	source, err := getEC2TrafficEndpointE(ctx, client, opts.SourceInstanceID, opts.SourceGroupID)
+	require.NoError(t, err)
+	target, err := getEC2TrafficEndpointE(ctx, client, opts.TargetInstanceID, opts.TargetGroupID)
+	require.NoError(t, err)
+
+	securityGroups, err := getEC2SecurityGroupsByIDE(ctx, client, append(append([]string{}, source.groupIDs...), target.groupIDs...))
+	require.NoError(t, err)
+
+	for _, groupID := range source.groupIDs {
+		if ec2IPPermissionsAllowEndpoint(securityGroups[groupID].IpPermissionsEgress, opts.Protocol, *opts.Port, target) {
+			egressAllowed = true
+		}
+	}
+	for _, groupID := range target.groupIDs {
+		if ec2IPPermissionsAllowEndpoint(securityGroups[groupID].IpPermissions, opts.Protocol, *opts.Port, source) {
+			ingressAllowed = true
+		}
+	}
+
+	description = fmt.Sprintf("%s traffic from %s to %s on port %d", opts.Protocol, source.name, target.name, *opts.Port)
+	return
+}
+
+// getEC2TrafficEndpointE returns the security groups and IP addresses of either an instance or a security group.
+func getEC2TrafficEndpointE(ctx context.Context, client EC2Client, instanceID string, groupID string) (endpoint ec2TrafficEndpoint, err error) {
+	if instanceID == "" {
+		endpoint.name = fmt.Sprintf("security group '%s'", groupID)
+		endpoint.groupIDs = []string{groupID}
+		return
+	}
+
+	instance, err := getEC2InstanceByInstanceIDE(ctx, client, instanceID)
+	if err != nil {
+		return
+	}
+	endpoint.name = fmt.Sprintf("instance '%s'", instanceID)
+	for _, securityGroup := range instance.SecurityGroups {
+		endpoint.groupIDs = appendUnique(endpoint.groupIDs, *securityGroup.GroupId)
+	}
+	if instance.PrivateIpAddress != nil {
+		endpoint.ipAddresses = appendUnique(endpoint.ipAddresses, *instance.PrivateIpAddress)
+	}
+	for _, networkInterface := range instance.NetworkInterfaces {
+		for _, securityGroup := range networkInterface.Groups {
+			endpoint.groupIDs = appendUnique(endpoint.groupIDs, *securityGroup.GroupId)
+		}
+		for _, privateIPAddress := range networkInterface.PrivateIpAddresses {
+			if privateIPAddress.PrivateIpAddress != nil {
+				endpoint.ipAddresses = appendUnique(endpoint.ipAddresses, *privateIPAddress.PrivateIpAddress)
+			}
+		}
+		for _, ipv6Address := range networkInterface.Ipv6Addresses {
+			if ipv6Address.Ipv6Address != nil {
+				endpoint.ipAddresses = appendUnique(endpoint.ipAddresses, *ipv6Address.Ipv6Address)
+			}
+		}
+	}
+	return
+}
+
+// getEC2SecurityGroupsByIDE returns a map of security groups keyed by their IDs. An empty list of IDs returns an empty map, as
+// DescribeSecurityGroups would otherwise return every security group in the account.
+func getEC2SecurityGroupsByIDE(ctx context.Context, client EC2Client, groupIDs []string) (map[string]types.SecurityGroup, error) {
+	securityGroups := make(map[string]types.SecurityGroup)
+	if len(groupIDs) == 0 {
+		return securityGroups, nil
+	}
+
+	var uniqueGroupIDs []string
+	for _, groupID := range groupIDs {
+		uniqueGroupIDs = appendUnique(uniqueGroupIDs, groupID)
+	}
+	input := &ec2.DescribeSecurityGroupsInput{
+		GroupIds: uniqueGroupIDs,
+	}
+	output, err := client.DescribeSecurityGroups(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if output != nil {
+		for _, securityGroup := range output.SecurityGroups {
+			securityGroups[*securityGroup.GroupId] = securityGroup
+		}
+	}
+	for _, groupID := range groupIDs {
+		if _, ok := securityGroups[groupID]; !ok {
+			return nil, fmt.Errorf("security group with ID '%s' was not found", groupID)
+		}
+	}
+	return securityGroups, nil
+}
+
+// ec2IPPermissionsAllowEndpoint returns true if any of the permissions allows traffic on the given protocol and port to or from
+// the given endpoint.
+func ec2IPPermissionsAllowEndpoint(permissions []types.IpPermission, protocol string, port int32, endpoint ec2TrafficEndpoint) bool {
+	for _, permission := range permissions {
+		if !ec2IPPermissionMatchesProtocol(permission, protocol) || !ec2IPPermissionCoversPortRange(permission, port, port) {
+			continue
+		}
+		for _, groupID := range endpoint.groupIDs {
+			if ec2IPPermissionReferencesGroup(permission, groupID) {
+				return true
+			}
+		}
+		if len(getEC2IPPermissionWorldCIDRs(permission)) > 0 {
+			return true
+		}
+		for _, ipAddress := range endpoint.ipAddresses {
+			if ec2IPPermissionCoversCIDR(permission, ipAddressToCIDR(ipAddress)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ipAddressToCIDR returns a CIDR block containing only the given IPv4 or IPv6 address.
+func ipAddressToCIDR(ipAddress string) string {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ""
+	}
+	if ip.To4() != nil {
+		return ipAddress + "/32"
+	}
+	return ipAddress + "/128"
+}
@@ -0,0 +1,369 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestEC2ReachabilityInstance returns the output of describing one instance with a private IP address and a security group.
+func newTestEC2ReachabilityInstance(instanceID string, privateIPAddress string, groupID string) *ec2.DescribeInstancesOutput {
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{
+					{
+						InstanceId:       aws.String(instanceID),
+						PrivateIpAddress: aws.String(privateIPAddress),
+						SecurityGroups: []types.GroupIdentifier{
+							{GroupId: aws.String(groupID)},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newTestEC2ReachabilitySecurityGroups returns an application security group that allows PostgreSQL traffic to the database
+// security group, and a database security group with the given ingress rules.
+func newTestEC2ReachabilitySecurityGroups(databaseIngress []types.IpPermission) *ec2.DescribeSecurityGroupsOutput {
+	return &ec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []types.SecurityGroup{
+			{
+				GroupId: aws.String("sg-app"),
+				IpPermissionsEgress: []types.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int32(5432),
+						ToPort:     aws.Int32(5432),
+						UserIdGroupPairs: []types.UserIdGroupPair{
+							{GroupId: aws.String("sg-db")},
+						},
+					},
+				},
+			},
+			{
+				GroupId:       aws.String("sg-db"),
+				IpPermissions: databaseIngress,
+			},
+		},
+	}
+}
+
+func TestAssertEC2TrafficAllowed_GroupReference(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	databaseIngress := []types.IpPermission{
+		{
+			IpProtocol: aws.String("tcp"),
+			FromPort:   aws.Int32(5432),
+			ToPort:     aws.Int32(5432),
+			UserIdGroupPairs: []types.UserIdGroupPair{
+				{GroupId: aws.String("sg-app")},
+			},
+		},
+	}
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-app"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-app", "10.0.1.10", "sg-app"), nil)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-db", "10.0.2.20", "sg-db"), nil)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-app", "sg-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilitySecurityGroups(databaseIngress), nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2TrafficAllowed(fakeTest, ctx, clientMock,
+		WithEC2TrafficSourceInstanceID("i-app"),
+		WithEC2TrafficTargetInstanceID("i-db"),
+		WithEC2TrafficPort(5432),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2TrafficAllowed_SourceCIDR(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	databaseIngress := []types.IpPermission{
+		{
+			IpProtocol: aws.String("tcp"),
+			FromPort:   aws.Int32(5432),
+			ToPort:     aws.Int32(5432),
+			IpRanges: []types.IpRange{
+				{CidrIp: aws.String("10.0.1.0/24")},
+			},
+		},
+	}
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-app"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-app", "10.0.1.10", "sg-app"), nil)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-db", "10.0.2.20", "sg-db"), nil)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-app", "sg-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilitySecurityGroups(databaseIngress), nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2TrafficAllowed(fakeTest, ctx, clientMock,
+		WithEC2TrafficSourceInstanceID("i-app"),
+		WithEC2TrafficTargetInstanceID("i-db"),
+		WithEC2TrafficPort(5432),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2TrafficAllowed_NoIngress(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	databaseIngress := []types.IpPermission{
+		{
+			IpProtocol: aws.String("tcp"),
+			FromPort:   aws.Int32(5432),
+			ToPort:     aws.Int32(5432),
+			IpRanges: []types.IpRange{
+				{CidrIp: aws.String("10.0.3.0/24")},
+			},
+		},
+	}
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-app"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-app", "10.0.1.10", "sg-app"), nil)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-db", "10.0.2.20", "sg-db"), nil)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-app", "sg-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilitySecurityGroups(databaseIngress), nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2TrafficAllowed(fakeTest, ctx, clientMock,
+		WithEC2TrafficSourceInstanceID("i-app"),
+		WithEC2TrafficTargetInstanceID("i-db"),
+		WithEC2TrafficPort(5432),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2TrafficDenied_WrongPort(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	databaseIngress := []types.IpPermission{
+		{
+			IpProtocol: aws.String("-1"),
+			UserIdGroupPairs: []types.UserIdGroupPair{
+				{GroupId: aws.String("sg-app")},
+			},
+		},
+	}
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-app"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-app", "10.0.1.10", "sg-app"), nil)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-db", "10.0.2.20", "sg-db"), nil)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-app", "sg-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilitySecurityGroups(databaseIngress), nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2TrafficDenied(fakeTest, ctx, clientMock,
+		WithEC2TrafficSourceInstanceID("i-app"),
+		WithEC2TrafficTargetInstanceID("i-db"),
+		WithEC2TrafficPort(22),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2TrafficDenied_Allowed(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	databaseIngress := []types.IpPermission{
+		{
+			IpProtocol: aws.String("-1"),
+			UserIdGroupPairs: []types.UserIdGroupPair{
+				{GroupId: aws.String("sg-app")},
+			},
+		},
+	}
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-app"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-app", "10.0.1.10", "sg-app"), nil)
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilityInstance("i-db", "10.0.2.20", "sg-db"), nil)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-app", "sg-db"}}).
+		Times(1).
+		Return(newTestEC2ReachabilitySecurityGroups(databaseIngress), nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2TrafficDenied(fakeTest, ctx, clientMock,
+		WithEC2TrafficSourceInstanceID("i-app"),
+		WithEC2TrafficTargetInstanceID("i-db"),
+		WithEC2TrafficPort(5432),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2TrafficAllowed_SecurityGroups(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	output := &ec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []types.SecurityGroup{
+			{
+				GroupId: aws.String("sg-lb"),
+				IpPermissionsEgress: []types.IpPermission{
+					{
+						IpProtocol: aws.String("-1"),
+						IpRanges: []types.IpRange{
+							{CidrIp: aws.String("0.0.0.0/0")},
+						},
+					},
+				},
+			},
+			{
+				GroupId: aws.String("sg-web"),
+				IpPermissions: []types.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int32(8080),
+						ToPort:     aws.Int32(8080),
+						UserIdGroupPairs: []types.UserIdGroupPair{
+							{GroupId: aws.String("sg-lb")},
+						},
+					},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-lb", "sg-web"}}).Times(1).Return(output, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2TrafficAllowed(fakeTest, ctx, clientMock,
+		WithEC2TrafficSourceGroupID("sg-lb"),
+		WithEC2TrafficTargetGroupID("sg-web"),
+		WithEC2TrafficPort(8080),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2TrafficDenied_NoSecurityGroups(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	for _, instanceID := range []string{"i-app", "i-db"} {
+		output := &ec2.DescribeInstancesOutput{
+			Reservations: []types.Reservation{
+				{
+					Instances: []types.Instance{
+						{InstanceId: aws.String(instanceID)},
+					},
+				},
+			},
+		}
+		clientMock.EXPECT().DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{instanceID}}).Times(1).Return(output, nil)
+	}
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2TrafficDenied(fakeTest, ctx, clientMock,
+		WithEC2TrafficSourceInstanceID("i-app"),
+		WithEC2TrafficTargetInstanceID("i-db"),
+		WithEC2TrafficPort(5432),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestGetEC2TrafficEndpointE_UniqueIPAddresses(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	output := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{
+					{
+						InstanceId:       aws.String("i-app"),
+						PrivateIpAddress: aws.String("10.0.1.10"),
+						NetworkInterfaces: []types.InstanceNetworkInterface{
+							{
+								PrivateIpAddresses: []types.InstancePrivateIpAddress{
+									{PrivateIpAddress: aws.String("10.0.1.10")},
+									{PrivateIpAddress: aws.String("10.0.1.11")},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-app"}}).Times(1).Return(output, nil)
+
+	// Execute
+	endpoint, err := getEC2TrafficEndpointE(ctx, clientMock, "i-app", "")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.1.10", "10.0.1.11"}, endpoint.ipAddresses)
+}
@@ -0,0 +1,446 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ec2ProtocolAll string = "-1"
+)
+
+// ec2ProtocolNumbers maps the protocol names accepted by the EC2 API to the protocol numbers that it may return instead.
+var ec2ProtocolNumbers = map[string]string{
+	"all":    ec2ProtocolAll,
+	"icmp":   "1",
+	"tcp":    "6",
+	"udp":    "17",
+	"icmpv6": "58",
+}
+
+// EC2SecurityGroupRuleOptions is a struct used for functional options for the security group rule assertion methods.
+// Any attribute that is left unset is not matched against.
+type EC2SecurityGroupRuleOptions struct {
+	// The protocol of the rule, either as a name (e.g. "tcp") or number (e.g. "6"). "-1" matches all protocols.
+	Protocol string
+	// The first port in the port range of the rule.
+	FromPort *int32
+	// The last port in the port range of the rule.
+	ToPort *int32
+	// An IPv4 or IPv6 CIDR block that the rule allows traffic from (ingress) or to (egress).
+	CIDR string
+	// The ID of a managed prefix list referenced by the rule.
+	PrefixListID string
+	// The ID of a security group referenced by the rule.
+	ReferencedGroupID string
+}
+
+// EC2SecurityGroupRuleOptsFunc is a type used for functional options for the security group rule assertion methods.
+type EC2SecurityGroupRuleOptsFunc func(*EC2SecurityGroupRuleOptions) error
+
+// WithEC2SecurityGroupRuleProtocol sets the protocol that a security group rule must allow.
+func WithEC2SecurityGroupRuleProtocol(protocol string) EC2SecurityGroupRuleOptsFunc {
+	return func(opts *EC2SecurityGroupRuleOptions) error {
+		opts.Protocol = protocol
+		return nil
+	}
+}
+
+// WithEC2SecurityGroupRulePort sets a single port that a security group rule must allow.
+func WithEC2SecurityGroupRulePort(port int32) EC2SecurityGroupRuleOptsFunc {
+	return WithEC2SecurityGroupRulePortRange(port, port)
+}
+
+// WithEC2SecurityGroupRulePortRange sets the range of ports that a security group rule must allow.
+func WithEC2SecurityGroupRulePortRange(fromPort int32, toPort int32) EC2SecurityGroupRuleOptsFunc {
+	return func(opts *EC2SecurityGroupRuleOptions) error {
+		if fromPort > toPort {
+			return fmt.Errorf("port range start %d is greater than port range end %d", fromPort, toPort)
+		}
+		opts.FromPort = &fromPort
+		opts.ToPort = &toPort
+		return nil
+	}
+}
+
+// WithEC2SecurityGroupRuleCIDR sets the IPv4 or IPv6 CIDR block that a security group rule must allow.
+func WithEC2SecurityGroupRuleCIDR(cidr string) EC2SecurityGroupRuleOptsFunc {
+	return func(opts *EC2SecurityGroupRuleOptions) error {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return err
+		}
+		opts.CIDR = cidr
+		return nil
+	}
+}
+
+// WithEC2SecurityGroupRulePrefixListID sets the ID of the managed prefix list that a security group rule must reference.
+func WithEC2SecurityGroupRulePrefixListID(prefixListID string) EC2SecurityGroupRuleOptsFunc {
+	return func(opts *EC2SecurityGroupRuleOptions) error {
+		opts.PrefixListID = prefixListID
+		return nil
+	}
+}
+
+// WithEC2SecurityGroupRuleReferencedGroupID sets the ID of the security group that a security group rule must reference.
+func WithEC2SecurityGroupRuleReferencedGroupID(groupID string) EC2SecurityGroupRuleOptsFunc {
+	return func(opts *EC2SecurityGroupRuleOptions) error {
+		opts.ReferencedGroupID = groupID
+		return nil
+	}
+}
+
+/*
+AssertEC2SecurityGroupIngressRule asserts that a security group has at least one ingress rule that allows the traffic described
+by the passed functional options. A rule allows the traffic if its protocol, port range and CIDR blocks cover the ones given,
+so a rule allowing all TCP ports from 10.0.0.0/8 will satisfy an assertion for port 443 from 10.1.0.0/16.
+
+# Examples
+
+Assert that a security group allows HTTPS from a VPC CIDR block.
+
+	AssertEC2SecurityGroupIngressRule(
+		t,
+		ctx,
+		client,
+		"sg-0123456789abcdef0",
+		WithEC2SecurityGroupRuleProtocol("tcp"),
+		WithEC2SecurityGroupRulePort(443),
+		WithEC2SecurityGroupRuleCIDR("10.0.0.0/16"),
+	)
+*/
+func AssertEC2SecurityGroupIngressRule(t *testing.T, ctx context.Context, client EC2Client, groupID string, optFns ...EC2SecurityGroupRuleOptsFunc) {
+	opts, securityGroup := getEC2SecurityGroupRuleAssertionInputs(t, ctx, client, groupID, optFns)
+	assert.True(t, ec2IPPermissionsContainRule(securityGroup.IpPermissions, opts), "Security group '%s' does not contain a matching ingress rule.", groupID)
+}
+
+// AssertEC2SecurityGroupEgressRule asserts that a security group has at least one egress rule that allows the traffic described
+// by the passed functional options. Rules are matched in the same way as the AssertEC2SecurityGroupIngressRule method.
+func AssertEC2SecurityGroupEgressRule(t *testing.T, ctx context.Context, client EC2Client, groupID string, optFns ...EC2SecurityGroupRuleOptsFunc) {
+	opts, securityGroup := getEC2SecurityGroupRuleAssertionInputs(t, ctx, client, groupID, optFns)
+	assert.True(t, ec2IPPermissionsContainRule(securityGroup.IpPermissionsEgress, opts), "Security group '%s' does not contain a matching egress rule.", groupID)
+}
+
+// AssertEC2SecurityGroupDoesNotContainIngressRule asserts that no ingress rule of a security group allows the traffic described
+// by the passed functional options. Rules are matched in the same way as the AssertEC2SecurityGroupIngressRule method.
+func AssertEC2SecurityGroupDoesNotContainIngressRule(t *testing.T, ctx context.Context, client EC2Client, groupID string, optFns ...EC2SecurityGroupRuleOptsFunc) {
+	opts, securityGroup := getEC2SecurityGroupRuleAssertionInputs(t, ctx, client, groupID, optFns)
+	assert.False(t, ec2IPPermissionsContainRule(securityGroup.IpPermissions, opts), "Security group '%s' contains a matching ingress rule.", groupID)
+}
+
+// AssertEC2SecurityGroupDoesNotContainEgressRule asserts that no egress rule of a security group allows the traffic described
+// by the passed functional options. Rules are matched in the same way as the AssertEC2SecurityGroupIngressRule method.
+func AssertEC2SecurityGroupDoesNotContainEgressRule(t *testing.T, ctx context.Context, client EC2Client, groupID string, optFns ...EC2SecurityGroupRuleOptsFunc) {
+	opts, securityGroup := getEC2SecurityGroupRuleAssertionInputs(t, ctx, client, groupID, optFns)
+	assert.False(t, ec2IPPermissionsContainRule(securityGroup.IpPermissionsEgress, opts), "Security group '%s' contains a matching egress rule.", groupID)
+}
+
+// getEC2SecurityGroupRuleAssertionInputs applies the functional options and retrieves the security group for the rule assertion methods,
+// failing the test immediately if either step returns an error.
+func getEC2SecurityGroupRuleAssertionInputs(t *testing.T, ctx context.Context, client EC2Client, groupID string, optFns []EC2SecurityGroupRuleOptsFunc) (EC2SecurityGroupRuleOptions, types.SecurityGroup) {
+	opts := EC2SecurityGroupRuleOptions{}
+	for _, optFn := range optFns {
+		err := optFn(&opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	securityGroup, err := getEC2SecurityGroupByIDE(ctx, client, groupID)
+	require.NoError(t, err)
+	return opts, securityGroup
+}
+
+func getEC2SecurityGroupByIDE(ctx context.Context, client EC2Client, groupID string) (types.SecurityGroup, error) {
+	input := &ec2.DescribeSecurityGroupsInput{
+		GroupIds: []string{groupID},
+	}
+	output, err := client.DescribeSecurityGroups(ctx, input)
+	if err != nil {
+		return types.SecurityGroup{}, err
+	}
+	if output == nil || len(output.SecurityGroups) == 0 {
+		err = fmt.Errorf("security group with ID '%s' was not found", groupID)
+		return types.SecurityGroup{}, err
+	}
+	return output.SecurityGroups[0], nil
+}
+
+// ec2IPPermissionsContainRule returns true if any of the given permissions allows the traffic described by the options.
+func ec2IPPermissionsContainRule(permissions []types.IpPermission, opts EC2SecurityGroupRuleOptions) bool {
+	for _, permission := range permissions {
+		if ec2IPPermissionMatches(permission, opts) {
+			return true
+		}
+	}
+	return false
+}
+
+// ec2IPPermissionMatches returns true if a single permission allows the traffic described by the options.
+func ec2IPPermissionMatches(permission types.IpPermission, opts EC2SecurityGroupRuleOptions) bool {
+	if !ec2IPPermissionMatchesProtocol(permission, opts.Protocol) {
+		return false
+	}
+	if opts.FromPort != nil && !ec2IPPermissionCoversPortRange(permission, *opts.FromPort, *opts.ToPort) {
+		return false
+	}
+	if opts.CIDR != "" && !ec2IPPermissionCoversCIDR(permission, opts.CIDR) {
+		return false
+	}
+	if opts.PrefixListID != "" && !ec2IPPermissionReferencesPrefixList(permission, opts.PrefixListID) {
+		return false
+	}
+	if opts.ReferencedGroupID != "" && !ec2IPPermissionReferencesGroup(permission, opts.ReferencedGroupID) {
+		return false
+	}
+	return true
+}
+
+func ec2IPPermissionMatchesProtocol(permission types.IpPermission, protocol string) bool {
+	if protocol == "" {
+		return true
+	}
+	permissionProtocol := normalizeEC2Protocol(permission.IpProtocol)
+	return permissionProtocol == ec2ProtocolAll || permissionProtocol == normalizeEC2Protocol(&protocol)
+}
+
+// ec2IPPermissionCoversPortRange returns true if the port range of a permission contains the given range. Permissions for all
+// protocols, or with a port range of -1 (as used by ICMP rules for "all types"), cover every port.
+func ec2IPPermissionCoversPortRange(permission types.IpPermission, fromPort int32, toPort int32) bool {
+	if normalizeEC2Protocol(permission.IpProtocol) == ec2ProtocolAll {
+		return true
+	}
+	if permission.FromPort == nil || permission.ToPort == nil || *permission.FromPort == -1 {
+		return true
+	}
+	return *permission.FromPort <= fromPort && toPort <= *permission.ToPort
+}
+
+// ec2IPPermissionCoversCIDR returns true if any of the IPv4 or IPv6 ranges of a permission contains the given CIDR block.
+func ec2IPPermissionCoversCIDR(permission types.IpPermission, cidr string) bool {
+	var permissionCIDRs []string
+	for _, ipRange := range permission.IpRanges {
+		if ipRange.CidrIp != nil {
+			permissionCIDRs = append(permissionCIDRs, *ipRange.CidrIp)
+		}
+	}
+	for _, ipv6Range := range permission.Ipv6Ranges {
+		if ipv6Range.CidrIpv6 != nil {
+			permissionCIDRs = append(permissionCIDRs, *ipv6Range.CidrIpv6)
+		}
+	}
+	for _, permissionCIDR := range permissionCIDRs {
+		if cidrContainsCIDR(permissionCIDR, cidr) {
+			return true
+		}
+	}
+	return false
+}
+
+func ec2IPPermissionReferencesPrefixList(permission types.IpPermission, prefixListID string) bool {
+	for _, prefixList := range permission.PrefixListIds {
+		if prefixList.PrefixListId != nil && *prefixList.PrefixListId == prefixListID {
+			return true
+		}
+	}
+	return false
+}
+
+func ec2IPPermissionReferencesGroup(permission types.IpPermission, groupID string) bool {
+	for _, groupPair := range permission.UserIdGroupPairs {
+		if groupPair.GroupId != nil && *groupPair.GroupId == groupID {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEC2Protocol converts a protocol name to the protocol number used by the EC2 API, so that both forms can be compared.
+func normalizeEC2Protocol(protocol *string) string {
+	if protocol == nil {
+		return ""
+	}
+	normalized := strings.ToLower(*protocol)
+	if number, ok := ec2ProtocolNumbers[normalized]; ok {
+		return number
+	}
+	return normalized
+}
+
+// cidrContainsCIDR returns true if the outer CIDR block contains every address of the inner CIDR block. Blocks of different
+// address families never contain each other, and unparseable blocks contain nothing.
+func cidrContainsCIDR(outer string, inner string) bool {
+	_, outerNet, err := net.ParseCIDR(outer)
+	if err != nil {
+		return false
+	}
+	_, innerNet, err := net.ParseCIDR(inner)
+	if err != nil {
+		return false
+	}
+	outerOnes, outerBits := outerNet.Mask.Size()
+	innerOnes, innerBits := innerNet.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && outerNet.Contains(innerNet.IP)
+}
+
+// AssertEC2SecurityGroupsNotOpenToWorldOptions is a struct used for functional options for the AssertEC2SecurityGroupsNotOpenToWorld method.
+type AssertEC2SecurityGroupsNotOpenToWorldOptions struct {
+	// Filters used to limit the security groups that are checked, in the format accepted by the CreateFiltersFromMap method.
+	Filters map[string][]string
+	// Ports that may be opened to the world. A rule is only exempted if every port in its range is in this list.
+	AllowedPorts []int32
+	// IDs of security groups that are exempt from the check.
+	AllowedGroupIDs []string
+}
+
+// AssertEC2SecurityGroupsNotOpenToWorldOptsFunc is a type used for functional options for the AssertEC2SecurityGroupsNotOpenToWorld method.
+type AssertEC2SecurityGroupsNotOpenToWorldOptsFunc func(*AssertEC2SecurityGroupsNotOpenToWorldOptions) error
+
+// WithEC2SecurityGroupsFilters limits the security groups checked by the AssertEC2SecurityGroupsNotOpenToWorld method to those
+// matching the given filters, e.g. `map[string][]string{"vpc-id": {"vpc-0123456789abcdef0"}}`.
+func WithEC2SecurityGroupsFilters(filters map[string][]string) AssertEC2SecurityGroupsNotOpenToWorldOptsFunc {
+	return func(opts *AssertEC2SecurityGroupsNotOpenToWorldOptions) error {
+		opts.Filters = filters
+		return nil
+	}
+}
+
+// WithEC2SecurityGroupsAllowedPorts sets the ports that the AssertEC2SecurityGroupsNotOpenToWorld method allows to be opened to the world.
+func WithEC2SecurityGroupsAllowedPorts(ports ...int32) AssertEC2SecurityGroupsNotOpenToWorldOptsFunc {
+	return func(opts *AssertEC2SecurityGroupsNotOpenToWorldOptions) error {
+		opts.AllowedPorts = append(opts.AllowedPorts, ports...)
+		return nil
+	}
+}
+
+// WithEC2SecurityGroupsAllowedGroupIDs sets the IDs of security groups that the AssertEC2SecurityGroupsNotOpenToWorld method ignores.
+func WithEC2SecurityGroupsAllowedGroupIDs(groupIDs ...string) AssertEC2SecurityGroupsNotOpenToWorldOptsFunc {
+	return func(opts *AssertEC2SecurityGroupsNotOpenToWorldOptions) error {
+		opts.AllowedGroupIDs = append(opts.AllowedGroupIDs, groupIDs...)
+		return nil
+	}
+}
+
+/*
+AssertEC2SecurityGroupsNotOpenToWorld asserts that no security group visible to the client has an ingress rule that allows traffic
+from 0.0.0.0/0 or ::/0. Every security group is checked, across all pages of results, and the test failure lists each offending
+group and rule. Rules for all protocols are always reported, since they cannot be limited to an allowed port.
+
+# Examples
+
+Assert that only HTTPS is open to the world in a single VPC, except for a known bastion security group.
+
+	AssertEC2SecurityGroupsNotOpenToWorld(
+		t,
+		ctx,
+		client,
+		WithEC2SecurityGroupsFilters(map[string][]string{"vpc-id": {"vpc-0123456789abcdef0"}}),
+		WithEC2SecurityGroupsAllowedPorts(443),
+		WithEC2SecurityGroupsAllowedGroupIDs("sg-0123456789abcdef0"),
+	)
+*/
+func AssertEC2SecurityGroupsNotOpenToWorld(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2SecurityGroupsNotOpenToWorldOptsFunc) {
+	opts := &AssertEC2SecurityGroupsNotOpenToWorldOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	securityGroups, err := getEC2SecurityGroupsE(ctx, client, CreateFiltersFromMap(opts.Filters))
+	require.NoError(t, err)
+
+	allowedGroupIDs := make(map[string]bool)
+	for _, groupID := range opts.AllowedGroupIDs {
+		allowedGroupIDs[groupID] = true
+	}
+	allowedPorts := make(map[int32]bool)
+	for _, port := range opts.AllowedPorts {
+		allowedPorts[port] = true
+	}
+
+	var violations []string
+	for _, securityGroup := range securityGroups {
+		if securityGroup.GroupId != nil && allowedGroupIDs[*securityGroup.GroupId] {
+			continue
+		}
+		for _, permission := range securityGroup.IpPermissions {
+			worldCIDRs := getEC2IPPermissionWorldCIDRs(permission)
+			if len(worldCIDRs) == 0 || ec2IPPermissionPortsAllowed(permission, allowedPorts) {
+				continue
+			}
+			violations = append(violations, fmt.Sprintf("%s: %s from %s", describeEC2SecurityGroup(securityGroup), describeEC2IPPermissionPorts(permission), strings.Join(worldCIDRs, ", ")))
+		}
+	}
+
+	assert.Empty(t, violations, "Security groups with ingress rules open to the world were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// getEC2IPPermissionWorldCIDRs returns the CIDR blocks of a permission that cover every IPv4 or IPv6 address.
+func getEC2IPPermissionWorldCIDRs(permission types.IpPermission) (cidrs []string) {
+	for _, ipRange := range permission.IpRanges {
+		if ipRange.CidrIp != nil && cidrContainsCIDR(*ipRange.CidrIp, "0.0.0.0/0") {
+			cidrs = append(cidrs, *ipRange.CidrIp)
+		}
+	}
+	for _, ipv6Range := range permission.Ipv6Ranges {
+		if ipv6Range.CidrIpv6 != nil && cidrContainsCIDR(*ipv6Range.CidrIpv6, "::/0") {
+			cidrs = append(cidrs, *ipv6Range.CidrIpv6)
+		}
+	}
+	return
+}
+
+// ec2IPPermissionPortsAllowed returns true if every port in the range of a permission is in the set of allowed ports.
+func ec2IPPermissionPortsAllowed(permission types.IpPermission, allowedPorts map[int32]bool) bool {
+	if normalizeEC2Protocol(permission.IpProtocol) == ec2ProtocolAll || permission.FromPort == nil || permission.ToPort == nil || *permission.FromPort == -1 {
+		return false
+	}
+	if int(*permission.ToPort-*permission.FromPort)+1 > len(allowedPorts) {
+		return false
+	}
+	for port := *permission.FromPort; port <= *permission.ToPort; port++ {
+		if !allowedPorts[port] {
+			return false
+		}
+	}
+	return true
+}
+
+// describeEC2SecurityGroup returns a human readable identifier for a security group, for use in test failure messages.
+func describeEC2SecurityGroup(securityGroup types.SecurityGroup) string {
+	groupID := ""
+	if securityGroup.GroupId != nil {
+		groupID = *securityGroup.GroupId
+	}
+	if securityGroup.GroupName != nil {
+		return fmt.Sprintf("%s (%s)", groupID, *securityGroup.GroupName)
+	}
+	return groupID
+}
+
+// describeEC2IPPermissionPorts returns a human readable description of the protocol and ports of a permission, for use in
+// test failure messages.
+func describeEC2IPPermissionPorts(permission types.IpPermission) string {
+	protocol := ""
+	if permission.IpProtocol != nil {
+		protocol = *permission.IpProtocol
+	}
+	if normalizeEC2Protocol(permission.IpProtocol) == ec2ProtocolAll {
+		return "all traffic"
+	}
+	if permission.FromPort == nil || permission.ToPort == nil || *permission.FromPort == -1 {
+		return fmt.Sprintf("%s all ports", protocol)
+	}
+	if *permission.FromPort == *permission.ToPort {
+		return fmt.Sprintf("%s port %d", protocol, *permission.FromPort)
+	}
+	return fmt.Sprintf("%s ports %d-%d", protocol, *permission.FromPort, *permission.ToPort)
+}
@@ -0,0 +1,333 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEC2SecurityGroup() types.SecurityGroup {
+	return types.SecurityGroup{
+		GroupId: aws.String("sg-123456"),
+		IpPermissions: []types.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int32(443),
+				ToPort:     aws.Int32(443),
+				IpRanges: []types.IpRange{
+					{CidrIp: aws.String("10.0.0.0/8")},
+				},
+				Ipv6Ranges: []types.Ipv6Range{
+					{CidrIpv6: aws.String("2600:1f18::/40")},
+				},
+			},
+			{
+				IpProtocol: aws.String("6"),
+				FromPort:   aws.Int32(8000),
+				ToPort:     aws.Int32(8100),
+				UserIdGroupPairs: []types.UserIdGroupPair{
+					{GroupId: aws.String("sg-654321")},
+				},
+				PrefixListIds: []types.PrefixListId{
+					{PrefixListId: aws.String("pl-123456")},
+				},
+			},
+		},
+		IpPermissionsEgress: []types.IpPermission{
+			{
+				IpProtocol: aws.String("-1"),
+				IpRanges: []types.IpRange{
+					{CidrIp: aws.String("0.0.0.0/0")},
+				},
+			},
+		},
+	}
+}
+
+func TestAssertEC2SecurityGroupIngressRule_MatchCIDR(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{newTestEC2SecurityGroup()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupIngressRule(fakeTest, ctx, clientMock, "sg-123456",
+		WithEC2SecurityGroupRuleProtocol("tcp"),
+		WithEC2SecurityGroupRulePort(443),
+		WithEC2SecurityGroupRuleCIDR("10.1.0.0/16"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SecurityGroupIngressRule_MatchIPv6CIDR(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{newTestEC2SecurityGroup()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupIngressRule(fakeTest, ctx, clientMock, "sg-123456",
+		WithEC2SecurityGroupRulePort(443),
+		WithEC2SecurityGroupRuleCIDR("2600:1f18:34::/48"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SecurityGroupIngressRule_MatchReferencedGroupAndPrefixList(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{newTestEC2SecurityGroup()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupIngressRule(fakeTest, ctx, clientMock, "sg-123456",
+		WithEC2SecurityGroupRuleProtocol("tcp"),
+		WithEC2SecurityGroupRulePortRange(8010, 8020),
+		WithEC2SecurityGroupRuleReferencedGroupID("sg-654321"),
+		WithEC2SecurityGroupRulePrefixListID("pl-123456"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SecurityGroupIngressRule_NoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{newTestEC2SecurityGroup()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupIngressRule(fakeTest, ctx, clientMock, "sg-123456",
+		WithEC2SecurityGroupRuleProtocol("tcp"),
+		WithEC2SecurityGroupRulePort(443),
+		WithEC2SecurityGroupRuleCIDR("0.0.0.0/0"),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SecurityGroupEgressRule_MatchAllProtocols(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{newTestEC2SecurityGroup()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupEgressRule(fakeTest, ctx, clientMock, "sg-123456",
+		WithEC2SecurityGroupRuleProtocol("udp"),
+		WithEC2SecurityGroupRulePort(53),
+		WithEC2SecurityGroupRuleCIDR("8.8.8.8/32"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SecurityGroupDoesNotContainIngressRule_NoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{newTestEC2SecurityGroup()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupDoesNotContainIngressRule(fakeTest, ctx, clientMock, "sg-123456",
+		WithEC2SecurityGroupRulePort(22),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SecurityGroupDoesNotContainEgressRule_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{GroupIds: []string{"sg-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []types.SecurityGroup{newTestEC2SecurityGroup()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupDoesNotContainEgressRule(fakeTest, ctx, clientMock, "sg-123456",
+		WithEC2SecurityGroupRuleCIDR("0.0.0.0/0"),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestWithEC2SecurityGroupRulePortRange_InvalidRange(t *testing.T) {
+	opts := EC2SecurityGroupRuleOptions{}
+
+	err := WithEC2SecurityGroupRulePortRange(443, 80)(&opts)
+
+	assert.Error(t, err)
+}
+
+func TestCIDRContainsCIDR(t *testing.T) {
+	assert.True(t, cidrContainsCIDR("10.0.0.0/8", "10.1.2.0/24"))
+	assert.True(t, cidrContainsCIDR("10.0.0.0/8", "10.0.0.0/8"))
+	assert.False(t, cidrContainsCIDR("10.1.0.0/16", "10.0.0.0/8"))
+	assert.False(t, cidrContainsCIDR("0.0.0.0/0", "::/0"))
+	assert.True(t, cidrContainsCIDR("::/0", "2600:1f18::/40"))
+	assert.False(t, cidrContainsCIDR("not-a-cidr", "10.0.0.0/8"))
+}
+
+func newTestEC2WorldOpenSecurityGroups() []types.SecurityGroup {
+	return []types.SecurityGroup{
+		{
+			GroupId:   aws.String("sg-web"),
+			GroupName: aws.String("web"),
+			IpPermissions: []types.IpPermission{
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int32(443),
+					ToPort:     aws.Int32(443),
+					IpRanges: []types.IpRange{
+						{CidrIp: aws.String("0.0.0.0/0")},
+					},
+					Ipv6Ranges: []types.Ipv6Range{
+						{CidrIpv6: aws.String("::/0")},
+					},
+				},
+			},
+		},
+		{
+			GroupId:   aws.String("sg-bastion"),
+			GroupName: aws.String("bastion"),
+			IpPermissions: []types.IpPermission{
+				{
+					IpProtocol: aws.String("tcp"),
+					FromPort:   aws.Int32(22),
+					ToPort:     aws.Int32(22),
+					Ipv6Ranges: []types.Ipv6Range{
+						{CidrIpv6: aws.String("::/0")},
+					},
+				},
+			},
+		},
+		{
+			GroupId:   aws.String("sg-internal"),
+			GroupName: aws.String("internal"),
+			IpPermissions: []types.IpPermission{
+				{
+					IpProtocol: aws.String("-1"),
+					IpRanges: []types.IpRange{
+						{CidrIp: aws.String("10.0.0.0/8")},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestAssertEC2SecurityGroupsNotOpenToWorld_Paginated(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	securityGroups := newTestEC2WorldOpenSecurityGroups()
+	filterName := "vpc-id"
+	filters := []types.Filter{
+		{
+			Name:   &filterName,
+			Values: []string{"vpc-123456"},
+		},
+	}
+	nextToken := "page2"
+	gomock.InOrder(
+		clientMock.EXPECT().
+			DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{Filters: filters}).
+			Times(1).
+			Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: securityGroups[:1], NextToken: &nextToken}, nil),
+		clientMock.EXPECT().
+			DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{Filters: filters, NextToken: &nextToken}).
+			Times(1).
+			Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: securityGroups[1:]}, nil),
+	)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupsNotOpenToWorld(fakeTest, ctx, clientMock,
+		WithEC2SecurityGroupsFilters(map[string][]string{filterName: {"vpc-123456"}}),
+		WithEC2SecurityGroupsAllowedPorts(443),
+		WithEC2SecurityGroupsAllowedGroupIDs("sg-bastion"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SecurityGroupsNotOpenToWorld_Violations(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{}).
+		Times(1).
+		Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: newTestEC2WorldOpenSecurityGroups()}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupsNotOpenToWorld(fakeTest, ctx, clientMock, WithEC2SecurityGroupsAllowedPorts(443))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestEC2IPPermissionPortsAllowed(t *testing.T) {
+	allowedPorts := map[int32]bool{80: true, 443: true}
+
+	assert.True(t, ec2IPPermissionPortsAllowed(types.IpPermission{IpProtocol: aws.String("tcp"), FromPort: aws.Int32(443), ToPort: aws.Int32(443)}, allowedPorts))
+	assert.False(t, ec2IPPermissionPortsAllowed(types.IpPermission{IpProtocol: aws.String("tcp"), FromPort: aws.Int32(80), ToPort: aws.Int32(443)}, allowedPorts))
+	assert.False(t, ec2IPPermissionPortsAllowed(types.IpPermission{IpProtocol: aws.String("-1")}, allowedPorts))
+	assert.False(t, ec2IPPermissionPortsAllowed(types.IpPermission{IpProtocol: aws.String("icmp"), FromPort: aws.Int32(-1), ToPort: aws.Int32(-1)}, allowedPorts))
+}
@@ -0,0 +1,171 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	snapshotOwnerSelf string = "self"
+)
+
+// AssertEC2SnapshotsOptions is a struct used for functional options for the EC2 snapshot assertion methods.
+type AssertEC2SnapshotsOptions struct {
+	// Filters used to limit the snapshots that are checked, in the format accepted by the CreateFiltersFromMap method.
+	Filters map[string][]string
+	// The IDs of the AWS accounts that snapshots may be shared with.
+	AllowedAccountIDs []string
+	// The IDs or ARNs of the KMS keys that snapshots may be encrypted with. If empty, any key is allowed.
+	AllowedKMSKeyIDs []string
+}
+
+// AssertEC2SnapshotsOptsFunc is a type used for functional options for the EC2 snapshot assertion methods.
+type AssertEC2SnapshotsOptsFunc func(*AssertEC2SnapshotsOptions) error
+
+// WithEC2SnapshotsFilters limits the snapshots checked to those matching the given filters, e.g. `map[string][]string{"tag:team": {"payments"}}`.
+func WithEC2SnapshotsFilters(filters map[string][]string) AssertEC2SnapshotsOptsFunc {
+	return func(opts *AssertEC2SnapshotsOptions) error {
+		opts.Filters = filters
+		return nil
+	}
+}
+
+// WithEC2SnapshotsAllowedAccountIDs sets the IDs of the AWS accounts that snapshots may be shared with.
+func WithEC2SnapshotsAllowedAccountIDs(accountIDs ...string) AssertEC2SnapshotsOptsFunc {
+	return func(opts *AssertEC2SnapshotsOptions) error {
+		opts.AllowedAccountIDs = append(opts.AllowedAccountIDs, accountIDs...)
+		return nil
+	}
+}
+
+// WithEC2SnapshotsAllowedKMSKeyIDs sets the IDs or ARNs of the KMS keys that snapshots may be encrypted with.
+func WithEC2SnapshotsAllowedKMSKeyIDs(kmsKeyIDs ...string) AssertEC2SnapshotsOptsFunc {
+	return func(opts *AssertEC2SnapshotsOptions) error {
+		opts.AllowedKMSKeyIDs = append(opts.AllowedKMSKeyIDs, kmsKeyIDs...)
+		return nil
+	}
+}
+
+// AssertEC2SnapshotsEncrypted asserts that every EBS snapshot owned by the account, optionally limited by filters, is encrypted
+// and (optionally) encrypted with one of a set of allowed KMS keys. The test failure lists every offending snapshot.
+func AssertEC2SnapshotsEncrypted(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2SnapshotsOptsFunc) {
+	opts, snapshots := getEC2SnapshotAssertionInputs(t, ctx, client, optFns)
+
+	var violations []string
+	for _, snapshot := range snapshots {
+		if snapshot.Encrypted == nil || !*snapshot.Encrypted {
+			violations = append(violations, fmt.Sprintf("%s: not encrypted", *snapshot.SnapshotId))
+			continue
+		}
+		if len(opts.AllowedKMSKeyIDs) > 0 && !kmsKeyIDAllowed(snapshot.KmsKeyId, opts.AllowedKMSKeyIDs) {
+			kmsKeyID := ""
+			if snapshot.KmsKeyId != nil {
+				kmsKeyID = *snapshot.KmsKeyId
+			}
+			violations = append(violations, fmt.Sprintf("%s: encrypted with KMS key '%s', which is not allowed", *snapshot.SnapshotId, kmsKeyID))
+		}
+	}
+
+	assert.Empty(t, violations, "EBS snapshots that are not correctly encrypted were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// AssertEC2SnapshotsNotPublic asserts that no EBS snapshot owned by the account, optionally limited by filters, can be used by
+// every AWS account to create volumes. The test failure lists every public snapshot.
+func AssertEC2SnapshotsNotPublic(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2SnapshotsOptsFunc) {
+	_, snapshots := getEC2SnapshotAssertionInputs(t, ctx, client, optFns)
+
+	var violations []string
+	for _, snapshot := range snapshots {
+		permissions, err := getEC2SnapshotCreateVolumePermissionsE(ctx, client, *snapshot.SnapshotId)
+		require.NoError(t, err)
+		for _, permission := range permissions {
+			if permission.Group == types.PermissionGroupAll {
+				violations = append(violations, *snapshot.SnapshotId)
+			}
+		}
+	}
+
+	assert.Empty(t, violations, "Public EBS snapshots were found:\n%s", strings.Join(violations, "\n"))
+}
+
+/*
+AssertEC2SnapshotsOnlySharedWithAllowedAccounts asserts that every EBS snapshot owned by the account, optionally limited by filters,
+is only shared with accounts passed to the WithEC2SnapshotsAllowedAccountIDs functional option. Public snapshots are treated as
+shared with an account that is not allowed. The test failure lists every offending snapshot and account.
+
+# Examples
+
+Assert that snapshots are only shared with a disaster recovery account.
+
+	AssertEC2SnapshotsOnlySharedWithAllowedAccounts(t, ctx, client, WithEC2SnapshotsAllowedAccountIDs("123456789012"))
+*/
+func AssertEC2SnapshotsOnlySharedWithAllowedAccounts(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2SnapshotsOptsFunc) {
+	opts, snapshots := getEC2SnapshotAssertionInputs(t, ctx, client, optFns)
+
+	allowedAccountIDs := make(map[string]bool)
+	for _, accountID := range opts.AllowedAccountIDs {
+		allowedAccountIDs[accountID] = true
+	}
+
+	var violations []string
+	for _, snapshot := range snapshots {
+		permissions, err := getEC2SnapshotCreateVolumePermissionsE(ctx, client, *snapshot.SnapshotId)
+		require.NoError(t, err)
+		for _, permission := range permissions {
+			if permission.Group == types.PermissionGroupAll {
+				violations = append(violations, fmt.Sprintf("%s: shared publicly", *snapshot.SnapshotId))
+			}
+			if permission.UserId != nil && !allowedAccountIDs[*permission.UserId] {
+				violations = append(violations, fmt.Sprintf("%s: shared with account '%s'", *snapshot.SnapshotId, *permission.UserId))
+			}
+		}
+	}
+
+	assert.Empty(t, violations, "EBS snapshots shared with accounts that are not allowed were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// getEC2SnapshotAssertionInputs applies the functional options and retrieves the snapshots owned by the account for the snapshot
+// assertion methods, failing the test immediately if either step returns an error.
+func getEC2SnapshotAssertionInputs(t *testing.T, ctx context.Context, client EC2Client, optFns []AssertEC2SnapshotsOptsFunc) (*AssertEC2SnapshotsOptions, []types.Snapshot) {
+	opts := &AssertEC2SnapshotsOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	snapshots, err := getEC2SnapshotsE(ctx, client, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{snapshotOwnerSelf},
+		Filters:  CreateFiltersFromMap(opts.Filters),
+	})
+	require.NoError(t, err)
+	return opts, snapshots
+}
+
+// getEC2SnapshotsE returns every snapshot matching the given input, reading all pages of results.
+func getEC2SnapshotsE(ctx context.Context, client EC2Client, input *ec2.DescribeSnapshotsInput) ([]types.Snapshot, error) {
+	return collectPagesE(ctx, ec2.NewDescribeSnapshotsPaginator(client, input), func(output *ec2.DescribeSnapshotsOutput) []types.Snapshot {
+		return output.Snapshots
+	})
+}
+
+// getEC2SnapshotCreateVolumePermissionsE returns the accounts and groups that may create volumes from a snapshot.
+func getEC2SnapshotCreateVolumePermissionsE(ctx context.Context, client EC2Client, snapshotID string) ([]types.CreateVolumePermission, error) {
+	input := &ec2.DescribeSnapshotAttributeInput{
+		Attribute:  types.SnapshotAttributeNameCreateVolumePermission,
+		SnapshotId: &snapshotID,
+	}
+	output, err := client.DescribeSnapshotAttribute(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.CreateVolumePermissions, nil
+}
@@ -0,0 +1,152 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEC2SnapshotClient(t *testing.T, snapshots []types.Snapshot, permissions map[string][]types.CreateVolumePermission) *mock.MockEC2Client {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{OwnerIds: []string{"self"}}).
+		Times(1).
+		Return(&ec2.DescribeSnapshotsOutput{Snapshots: snapshots}, nil)
+	for snapshotID, snapshotPermissions := range permissions {
+		snapshotIDCopy := snapshotID
+		input := &ec2.DescribeSnapshotAttributeInput{
+			Attribute:  types.SnapshotAttributeNameCreateVolumePermission,
+			SnapshotId: &snapshotIDCopy,
+		}
+		clientMock.EXPECT().
+			DescribeSnapshotAttribute(ctx, input).
+			Times(1).
+			Return(&ec2.DescribeSnapshotAttributeOutput{SnapshotId: &snapshotIDCopy, CreateVolumePermissions: snapshotPermissions}, nil)
+	}
+	return clientMock
+}
+
+func TestAssertEC2SnapshotsEncrypted_Match(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2SnapshotClient(t, []types.Snapshot{
+		{
+			SnapshotId: aws.String("snap-1"),
+			Encrypted:  aws.Bool(true),
+			KmsKeyId:   aws.String("arn:aws:kms:us-east-1:123456789012:key/allowed"),
+		},
+	}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SnapshotsEncrypted(fakeTest, context.Background(), clientMock, WithEC2SnapshotsAllowedKMSKeyIDs("allowed"))
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SnapshotsEncrypted_NoMatch(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2SnapshotClient(t, []types.Snapshot{
+		{
+			SnapshotId: aws.String("snap-1"),
+			Encrypted:  aws.Bool(true),
+		},
+		{
+			SnapshotId: aws.String("snap-2"),
+			Encrypted:  aws.Bool(false),
+		},
+	}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SnapshotsEncrypted(fakeTest, context.Background(), clientMock)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SnapshotsNotPublic_Private(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2SnapshotClient(t, []types.Snapshot{
+		{SnapshotId: aws.String("snap-1")},
+	}, map[string][]types.CreateVolumePermission{
+		"snap-1": {
+			{UserId: aws.String("123456789012")},
+		},
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SnapshotsNotPublic(fakeTest, context.Background(), clientMock)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SnapshotsNotPublic_Public(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2SnapshotClient(t, []types.Snapshot{
+		{SnapshotId: aws.String("snap-1")},
+	}, map[string][]types.CreateVolumePermission{
+		"snap-1": {
+			{Group: types.PermissionGroupAll},
+		},
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SnapshotsNotPublic(fakeTest, context.Background(), clientMock)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SnapshotsOnlySharedWithAllowedAccounts_Allowed(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2SnapshotClient(t, []types.Snapshot{
+		{SnapshotId: aws.String("snap-1")},
+		{SnapshotId: aws.String("snap-2")},
+	}, map[string][]types.CreateVolumePermission{
+		"snap-1": {
+			{UserId: aws.String("123456789012")},
+		},
+		"snap-2": {},
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SnapshotsOnlySharedWithAllowedAccounts(fakeTest, context.Background(), clientMock, WithEC2SnapshotsAllowedAccountIDs("123456789012"))
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SnapshotsOnlySharedWithAllowedAccounts_NotAllowed(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2SnapshotClient(t, []types.Snapshot{
+		{SnapshotId: aws.String("snap-1")},
+	}, map[string][]types.CreateVolumePermission{
+		"snap-1": {
+			{UserId: aws.String("123456789012")},
+			{UserId: aws.String("210987654321")},
+		},
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SnapshotsOnlySharedWithAllowedAccounts(fakeTest, context.Background(), clientMock, WithEC2SnapshotsAllowedAccountIDs("123456789012"))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
@@ -0,0 +1,273 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// EC2TagCase is a casing rule that tag keys or values must follow.
+type EC2TagCase string
+
+const (
+	// EC2TagCaseAny places no restriction on casing.
+	EC2TagCaseAny EC2TagCase = ""
+	// EC2TagCaseLower requires text to be entirely lower case.
+	EC2TagCaseLower EC2TagCase = "lower"
+	// EC2TagCaseUpper requires text to be entirely upper case.
+	EC2TagCaseUpper EC2TagCase = "upper"
+)
+
+// awsReservedTagKeyPrefix is the prefix of tag keys that are managed by AWS, which are exempt from the casing rules of a tag policy.
+const awsReservedTagKeyPrefix = "aws:"
+
+/*
+EC2TagPolicy is a declarative set of tagging rules that can be evaluated against EC2 resources. Every rule that is left unset is not
+checked. Keys with the reserved "aws:" prefix are exempt from the casing rules, since they are managed by AWS.
+
+# Examples
+
+A policy requiring owner and environment tags, with a fixed set of environments and a cost center in the format "CC-1234".
+
+	policy := EC2TagPolicy{
+		RequiredKeys:  []string{"owner", "environment"},
+		AllowedValues: map[string][]string{"environment": {"dev", "staging", "prod"}},
+		ValuePatterns: map[string]string{"cost-center": `^CC-[0-9]{4}$`},
+		KeyCase:       EC2TagCaseLower,
+		ForbiddenKeys: []string{"temp"},
+	}
+*/
+type EC2TagPolicy struct {
+	// Keys that must be present.
+	RequiredKeys []string
+	// For each key, the set of values that tag may have if it is present.
+	AllowedValues map[string][]string
+	// For each key, a regular expression that the value of the tag must match if it is present.
+	ValuePatterns map[string]string
+	// The casing rule that every tag key must follow. Any value other than the EC2TagCase constants fails the test.
+	KeyCase EC2TagCase
+	// The casing rule that every tag value must follow. Any value other than the EC2TagCase constants fails the test.
+	ValueCase EC2TagCase
+	// Keys that must not be present.
+	ForbiddenKeys []string
+}
+
+// AssertEC2TagPolicyOptions is a struct used for functional options for the EC2 tag policy assertion methods.
+type AssertEC2TagPolicyOptions struct {
+	// Filters used to limit the resources that are checked, in the format accepted by the CreateFiltersFromMap method.
+	Filters map[string][]string
+}
+
+// AssertEC2TagPolicyOptsFunc is a type used for functional options for the EC2 tag policy assertion methods.
+type AssertEC2TagPolicyOptsFunc func(*AssertEC2TagPolicyOptions) error
+
+// WithEC2TagPolicyFilters limits the resources checked against a tag policy to those matching the given filters, e.g.
+// `map[string][]string{"tag:team": {"payments"}}`.
+func WithEC2TagPolicyFilters(filters map[string][]string) AssertEC2TagPolicyOptsFunc {
+	return func(opts *AssertEC2TagPolicyOptions) error {
+		opts.Filters = filters
+		return nil
+	}
+}
+
+/*
+AssertEC2InstancesTagPolicy asserts that the tags of every EC2 instance, optionally limited by filters, comply with a tag policy.
+The test failure lists every violation of every instance.
+
+# Examples
+
+Assert that the running instances of a team comply with a policy.
+
+	AssertEC2InstancesTagPolicy(t, ctx, client, policy, WithEC2TagPolicyFilters(map[string][]string{
+		"tag:team":            {"payments"},
+		"instance-state-name": {"running"},
+	}))
+*/
+func AssertEC2InstancesTagPolicy(t *testing.T, ctx context.Context, client EC2Client, policy EC2TagPolicy, optFns ...AssertEC2TagPolicyOptsFunc) {
+	opts := getEC2TagPolicyOptions(t, optFns)
+	patterns, err := compileEC2TagPolicyE(policy)
+	require.NoError(t, err)
+	instances, err := getEC2InstancesE(ctx, client, &ec2.DescribeInstancesInput{Filters: CreateFiltersFromMap(opts.Filters)})
+	require.NoError(t, err)
+
+	var violations []string
+	for _, instance := range instances {
+		resourceViolations := getEC2TagPolicyViolations(policy, patterns, instance.Tags)
+		violations = append(violations, prefixViolations(*instance.InstanceId, resourceViolations)...)
+	}
+
+	assert.Empty(t, violations, "EC2 instances that do not comply with the tag policy were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// AssertEC2VolumesTagPolicy asserts that the tags of every EBS volume, optionally limited by filters, comply with a tag policy.
+// The test failure lists every violation of every volume.
+func AssertEC2VolumesTagPolicy(t *testing.T, ctx context.Context, client EC2Client, policy EC2TagPolicy, optFns ...AssertEC2TagPolicyOptsFunc) {
+	opts := getEC2TagPolicyOptions(t, optFns)
+	patterns, err := compileEC2TagPolicyE(policy)
+	require.NoError(t, err)
+	volumes, err := getEC2VolumesE(ctx, client, &ec2.DescribeVolumesInput{Filters: CreateFiltersFromMap(opts.Filters)})
+	require.NoError(t, err)
+
+	var violations []string
+	for _, volume := range volumes {
+		resourceViolations := getEC2TagPolicyViolations(policy, patterns, volume.Tags)
+		violations = append(violations, prefixViolations(*volume.VolumeId, resourceViolations)...)
+	}
+
+	assert.Empty(t, violations, "EBS volumes that do not comply with the tag policy were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// AssertEC2SnapshotsTagPolicy asserts that the tags of every EBS snapshot owned by the account, optionally limited by filters,
+// comply with a tag policy. The test failure lists every violation of every snapshot.
+func AssertEC2SnapshotsTagPolicy(t *testing.T, ctx context.Context, client EC2Client, policy EC2TagPolicy, optFns ...AssertEC2TagPolicyOptsFunc) {
+	opts := getEC2TagPolicyOptions(t, optFns)
+	patterns, err := compileEC2TagPolicyE(policy)
+	require.NoError(t, err)
+	snapshots, err := getEC2SnapshotsE(ctx, client, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{snapshotOwnerSelf},
+		Filters:  CreateFiltersFromMap(opts.Filters),
+	})
+	require.NoError(t, err)
+
+	var violations []string
+	for _, snapshot := range snapshots {
+		resourceViolations := getEC2TagPolicyViolations(policy, patterns, snapshot.Tags)
+		violations = append(violations, prefixViolations(*snapshot.SnapshotId, resourceViolations)...)
+	}
+
+	assert.Empty(t, violations, "EBS snapshots that do not comply with the tag policy were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// AssertEC2SecurityGroupsTagPolicy asserts that the tags of every security group, optionally limited by filters, comply with a tag
+// policy. The test failure lists every violation of every security group.
+func AssertEC2SecurityGroupsTagPolicy(t *testing.T, ctx context.Context, client EC2Client, policy EC2TagPolicy, optFns ...AssertEC2TagPolicyOptsFunc) {
+	opts := getEC2TagPolicyOptions(t, optFns)
+	patterns, err := compileEC2TagPolicyE(policy)
+	require.NoError(t, err)
+	securityGroups, err := getEC2SecurityGroupsE(ctx, client, CreateFiltersFromMap(opts.Filters))
+	require.NoError(t, err)
+
+	var violations []string
+	for _, securityGroup := range securityGroups {
+		resourceViolations := getEC2TagPolicyViolations(policy, patterns, securityGroup.Tags)
+		violations = append(violations, prefixViolations(*securityGroup.GroupId, resourceViolations)...)
+	}
+
+	assert.Empty(t, violations, "Security groups that do not comply with the tag policy were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// getEC2TagPolicyOptions applies the functional options for the tag policy assertion methods.
+func getEC2TagPolicyOptions(t *testing.T, optFns []AssertEC2TagPolicyOptsFunc) *AssertEC2TagPolicyOptions {
+	opts := &AssertEC2TagPolicyOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+	return opts
+}
+
+// prefixViolations prefixes each violation with the ID or description of the resource it belongs to.
+func prefixViolations(resourceID string, violations []string) (output []string) {
+	for _, violation := range violations {
+		output = append(output, fmt.Sprintf("%s: %s", resourceID, violation))
+	}
+	return
+}
+
+// compileEC2TagPolicyE checks a policy and compiles its value patterns, keyed by tag key. An error is returned if a casing rule is
+// not one of the EC2TagCase constants, or if any value pattern is not a valid regular expression, whether or not a resource has the tag.
+func compileEC2TagPolicyE(policy EC2TagPolicy) (map[string]*regexp.Regexp, error) {
+	for _, tagCase := range []EC2TagCase{policy.KeyCase, policy.ValueCase} {
+		if tagCase != EC2TagCaseAny && tagCase != EC2TagCaseLower && tagCase != EC2TagCaseUpper {
+			return nil, fmt.Errorf("casing rule '%s' is not one of '%s' or '%s'", tagCase, EC2TagCaseLower, EC2TagCaseUpper)
+		}
+	}
+
+	// Sort the keys so that errors are reported consistently.
+	keys := make([]string, 0, len(policy.ValuePatterns))
+	for key := range policy.ValuePatterns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	patterns := make(map[string]*regexp.Regexp, len(keys))
+	for _, key := range keys {
+		pattern, err := regexp.Compile(policy.ValuePatterns[key])
+		if err != nil {
+			return nil, fmt.Errorf("value pattern for tag '%s' is not a valid regular expression: %w", key, err)
+		}
+		patterns[key] = pattern
+	}
+	return patterns, nil
+}
+
+// getEC2TagPolicyViolations returns a description of every way in which a set of tags does not comply with a policy, in a stable
+// order. The policy must already be compiled with compileEC2TagPolicyE.
+func getEC2TagPolicyViolations(policy EC2TagPolicy, patterns map[string]*regexp.Regexp, tags []types.Tag) (violations []string) {
+	tagValues := make(map[string]string)
+	var tagKeys []string
+	for _, tag := range tags {
+		if tag.Key == nil {
+			continue
+		}
+		value := ""
+		if tag.Value != nil {
+			value = *tag.Value
+		}
+		tagValues[*tag.Key] = value
+		tagKeys = append(tagKeys, *tag.Key)
+	}
+	sort.Strings(tagKeys)
+
+	for _, key := range policy.RequiredKeys {
+		if _, ok := tagValues[key]; !ok {
+			violations = append(violations, fmt.Sprintf("required tag '%s' is missing", key))
+		}
+	}
+	for _, key := range policy.ForbiddenKeys {
+		if _, ok := tagValues[key]; ok {
+			violations = append(violations, fmt.Sprintf("forbidden tag '%s' is present", key))
+		}
+	}
+
+	for _, key := range tagKeys {
+		value := tagValues[key]
+		if allowedValues, ok := policy.AllowedValues[key]; ok && !stringInSlice(value, allowedValues) {
+			violations = append(violations, fmt.Sprintf("tag '%s' has value '%s', which is not one of the allowed values [%s]", key, value, strings.Join(allowedValues, ", ")))
+		}
+		if pattern, ok := patterns[key]; ok && !pattern.MatchString(value) {
+			violations = append(violations, fmt.Sprintf("tag '%s' has value '%s', which does not match the pattern '%s'", key, value, pattern))
+		}
+		if strings.HasPrefix(key, awsReservedTagKeyPrefix) {
+			continue
+		}
+		if !ec2TagCaseMatches(policy.KeyCase, key) {
+			violations = append(violations, fmt.Sprintf("tag key '%s' is not %s case", key, policy.KeyCase))
+		}
+		if !ec2TagCaseMatches(policy.ValueCase, value) {
+			violations = append(violations, fmt.Sprintf("tag '%s' has value '%s', which is not %s case", key, value, policy.ValueCase))
+		}
+	}
+	return
+}
+
+// ec2TagCaseMatches returns true if the given text follows a casing rule.
+func ec2TagCaseMatches(tagCase EC2TagCase, text string) bool {
+	switch tagCase {
+	case EC2TagCaseLower:
+		return text == strings.ToLower(text)
+	case EC2TagCaseUpper:
+		return text == strings.ToUpper(text)
+	}
+	return true
+}
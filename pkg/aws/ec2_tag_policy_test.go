@@ -0,0 +1,244 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEC2TagPolicyViolations_Compliant(t *testing.T) {
+	// Setup
+	policy := EC2TagPolicy{
+		RequiredKeys:  []string{"owner", "environment"},
+		AllowedValues: map[string][]string{"environment": {"dev", "prod"}},
+		ValuePatterns: map[string]string{"cost-center": `^CC-[0-9]{4}$`},
+		KeyCase:       EC2TagCaseLower,
+		ValueCase:     EC2TagCaseLower,
+		ForbiddenKeys: []string{"temp"},
+	}
+	tags := []types.Tag{
+		{Key: aws.String("owner"), Value: aws.String("payments")},
+		{Key: aws.String("environment"), Value: aws.String("prod")},
+		{Key: aws.String("aws:cloudformation:Stack-Name"), Value: aws.String("Stack")},
+	}
+	patterns, err := compileEC2TagPolicyE(policy)
+	require.NoError(t, err)
+
+	// Execute
+	violations := getEC2TagPolicyViolations(policy, patterns, tags)
+
+	// Assert
+	assert.Empty(t, violations)
+}
+
+func TestGetEC2TagPolicyViolations_AllViolations(t *testing.T) {
+	// Setup
+	policy := EC2TagPolicy{
+		RequiredKeys:  []string{"owner", "environment"},
+		AllowedValues: map[string][]string{"environment": {"dev", "prod"}},
+		ValuePatterns: map[string]string{"cost-center": `^CC-[0-9]{4}$`},
+		KeyCase:       EC2TagCaseLower,
+		ValueCase:     EC2TagCaseLower,
+		ForbiddenKeys: []string{"temp"},
+	}
+	tags := []types.Tag{
+		{Key: aws.String("environment"), Value: aws.String("qa")},
+		{Key: aws.String("cost-center"), Value: aws.String("cc-12")},
+		{Key: aws.String("Name"), Value: aws.String("web")},
+		{Key: aws.String("temp"), Value: aws.String("Yes")},
+	}
+	patterns, err := compileEC2TagPolicyE(policy)
+	require.NoError(t, err)
+
+	// Execute
+	violations := getEC2TagPolicyViolations(policy, patterns, tags)
+
+	// Assert
+	assert.Equal(t, []string{
+		"required tag 'owner' is missing",
+		"forbidden tag 'temp' is present",
+		"tag key 'Name' is not lower case",
+		"tag 'cost-center' has value 'cc-12', which does not match the pattern '^CC-[0-9]{4}$'",
+		"tag 'environment' has value 'qa', which is not one of the allowed values [dev, prod]",
+		"tag 'temp' has value 'Yes', which is not lower case",
+	}, violations)
+}
+
+func TestCompileEC2TagPolicyE_InvalidPattern(t *testing.T) {
+	// Setup
+	policy := EC2TagPolicy{ValuePatterns: map[string]string{"owner": "^[a-z]+$", "cost-center": "("}}
+
+	// Execute
+	_, err := compileEC2TagPolicyE(policy)
+
+	// Assert
+	assert.ErrorContains(t, err, "cost-center")
+}
+
+func TestCompileEC2TagPolicyE_UnknownCase(t *testing.T) {
+	// Setup
+	policy := EC2TagPolicy{KeyCase: EC2TagCaseLower, ValueCase: "Lower"}
+
+	// Execute
+	_, err := compileEC2TagPolicyE(policy)
+
+	// Assert
+	assert.ErrorContains(t, err, "Lower")
+}
+
+func TestAssertEC2InstancesTagPolicy_Violations(t *testing.T) {
+	// Setup
+	policy := EC2TagPolicy{
+		RequiredKeys:  []string{"owner", "environment"},
+		AllowedValues: map[string][]string{"environment": {"dev", "prod"}},
+		ValuePatterns: map[string]string{"cost-center": `^CC-[0-9]{4}$`},
+		KeyCase:       EC2TagCaseLower,
+		ValueCase:     EC2TagCaseLower,
+		ForbiddenKeys: []string{"temp"},
+	}
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	filters := map[string][]string{"tag:team": {"payments"}}
+	output := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{
+					{
+						InstanceId: aws.String("i-123456"),
+						Tags: []types.Tag{
+							{Key: aws.String("owner"), Value: aws.String("payments")},
+							{Key: aws.String("environment"), Value: aws.String("prod")},
+						},
+					},
+					{
+						InstanceId: aws.String("i-654321"),
+						Tags:       []types.Tag{{Key: aws.String("owner"), Value: aws.String("payments")}},
+					},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeInstances(ctx, &ec2.DescribeInstancesInput{Filters: CreateFiltersFromMap(filters)}).Times(1).Return(output, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstancesTagPolicy(fakeTest, ctx, clientMock, policy, WithEC2TagPolicyFilters(filters))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2VolumesTagPolicy_Compliant(t *testing.T) {
+	// Setup
+	policy := EC2TagPolicy{
+		RequiredKeys:  []string{"owner", "environment"},
+		AllowedValues: map[string][]string{"environment": {"dev", "prod"}},
+		ValuePatterns: map[string]string{"cost-center": `^CC-[0-9]{4}$`},
+		KeyCase:       EC2TagCaseLower,
+		ValueCase:     EC2TagCaseLower,
+		ForbiddenKeys: []string{"temp"},
+	}
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	output := &ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{
+				VolumeId: aws.String("vol-123456"),
+				Tags: []types.Tag{
+					{Key: aws.String("owner"), Value: aws.String("payments")},
+					{Key: aws.String("environment"), Value: aws.String("dev")},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeVolumes(ctx, &ec2.DescribeVolumesInput{}).Times(1).Return(output, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2VolumesTagPolicy(fakeTest, ctx, clientMock, policy)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SnapshotsTagPolicy_Violations(t *testing.T) {
+	// Setup
+	policy := EC2TagPolicy{
+		RequiredKeys:  []string{"owner", "environment"},
+		AllowedValues: map[string][]string{"environment": {"dev", "prod"}},
+		ValuePatterns: map[string]string{"cost-center": `^CC-[0-9]{4}$`},
+		KeyCase:       EC2TagCaseLower,
+		ValueCase:     EC2TagCaseLower,
+		ForbiddenKeys: []string{"temp"},
+	}
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	output := &ec2.DescribeSnapshotsOutput{
+		Snapshots: []types.Snapshot{
+			{
+				SnapshotId: aws.String("snap-123456"),
+				Tags: []types.Tag{
+					{Key: aws.String("owner"), Value: aws.String("payments")},
+					{Key: aws.String("environment"), Value: aws.String("prod")},
+					{Key: aws.String("temp"), Value: aws.String("true")},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{OwnerIds: []string{"self"}}).Times(1).Return(output, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SnapshotsTagPolicy(fakeTest, ctx, clientMock, policy)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2SecurityGroupsTagPolicy_Compliant(t *testing.T) {
+	// Setup
+	policy := EC2TagPolicy{
+		RequiredKeys:  []string{"owner", "environment"},
+		AllowedValues: map[string][]string{"environment": {"dev", "prod"}},
+		ValuePatterns: map[string]string{"cost-center": `^CC-[0-9]{4}$`},
+		KeyCase:       EC2TagCaseLower,
+		ValueCase:     EC2TagCaseAny,
+		ForbiddenKeys: []string{"temp"},
+	}
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	output := &ec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []types.SecurityGroup{
+			{
+				GroupId: aws.String("sg-123456"),
+				Tags: []types.Tag{
+					{Key: aws.String("owner"), Value: aws.String("payments")},
+					{Key: aws.String("environment"), Value: aws.String("prod")},
+					{Key: aws.String("cost-center"), Value: aws.String("CC-1234")},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{}).Times(1).Return(output, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2SecurityGroupsTagPolicy(fakeTest, ctx, clientMock, policy)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
@@ -64,6 +64,21 @@ func (c EC2ClientMock) DescribeSecurityGroups(ctx context.Context, input *ec2.De
 	return nil, nil
 }
 
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) GetEbsEncryptionByDefault(ctx context.Context, input *ec2.GetEbsEncryptionByDefaultInput, optFns ...func(*ec2.Options)) (*ec2.GetEbsEncryptionByDefaultOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeSnapshots(ctx context.Context, input *ec2.DescribeSnapshotsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeSnapshotAttribute(ctx context.Context, input *ec2.DescribeSnapshotAttributeInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSnapshotAttributeOutput, error) {
+	return nil, nil
+}
+
 func TestAssertEC2VolumeEncryptedE_Match(t *testing.T) {
 	// Setup
 	instanceID := "i546acas321sd"
@@ -612,11 +627,11 @@ func TestGetEC2InstancesByTag(t *testing.T) {
 	ctx := context.Background()
 
 	// Execute
-	actualOutput, err := getEC2InstancesByTagE(ctx, clientMock, tags)
+	actualOutput, err := GetEC2InstancesByTagE(ctx, clientMock, tags)
 
 	// Assert
-	assert.Nil(t, err, "getEC2InstancesByTagE returned an unexpected error")
-	assert.ElementsMatch(t, expectedOutput, actualOutput, "getEC2InstancesByTagE did not return the expected results")
+	assert.Nil(t, err, "GetEC2InstancesByTagE returned an unexpected error")
+	assert.ElementsMatch(t, expectedOutput, actualOutput, "GetEC2InstancesByTagE did not return the expected results")
 }
 
 func TestAssertEC2InstancesSubnetBalanced_Balanced(t *testing.T) {
@@ -1208,3 +1223,238 @@ func TestAssertEC2VolumeTagValue_NoTag(t *testing.T) {
 	AssertEC2VolumeTagValue(fakeTest, ctx, clientMock, assertEC2VolumeTagValueInput)
 	assert.True(t, fakeTest.Failed(), "AssertEC2VolumeTagValue did not fail the test when tag is not found.")
 }
+
+func newTestEC2InstancesInAvailabilityZones(t *testing.T, instances []types.Instance) (*mock.MockEC2Client, map[string][]string) {
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	tags := map[string][]string{
+		"service": {"api"},
+	}
+	output := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: instances,
+			},
+		},
+	}
+	clientMock.EXPECT().
+		DescribeInstances(gomock.Any(), &ec2.DescribeInstancesInput{Filters: CreateFiltersFromMap(map[string][]string{"tag:service": {"api"}})}).
+		Times(1).
+		Return(output, nil)
+	return clientMock, tags
+}
+
+func newTestEC2InstanceInAvailabilityZone(availabilityZone string, state types.InstanceStateName) types.Instance {
+	return types.Instance{
+		Placement: &types.Placement{
+			AvailabilityZone: aws.String(availabilityZone),
+		},
+		State: &types.InstanceState{
+			Name: state,
+		},
+	}
+}
+
+func TestAssertEC2InstancesBalancedInAvailabilityZones_Balanced(t *testing.T) {
+	// Setup
+	clientMock, tags := newTestEC2InstancesInAvailabilityZones(t, []types.Instance{
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1b", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1c", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameStopped),
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameTerminated),
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstancesBalancedInAvailabilityZones(fakeTest, context.Background(), clientMock, tags, WithEC2InstancesMinimumAvailabilityZones(3))
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstancesBalancedInAvailabilityZones_Unbalanced(t *testing.T) {
+	// Setup
+	clientMock, tags := newTestEC2InstancesInAvailabilityZones(t, []types.Instance{
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1b", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1b", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1b", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1c", types.InstanceStateNameRunning),
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstancesBalancedInAvailabilityZones(fakeTest, context.Background(), clientMock, tags)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstancesBalancedInAvailabilityZones_NoIgnoredStates(t *testing.T) {
+	// Setup
+	clientMock, tags := newTestEC2InstancesInAvailabilityZones(t, []types.Instance{
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1b", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameStopped),
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameStopped),
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstancesBalancedInAvailabilityZones(fakeTest, context.Background(), clientMock, tags, WithEC2InstancesIgnoredStates())
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstancesBalancedInAvailabilityZones_TooFewAvailabilityZones(t *testing.T) {
+	// Setup
+	clientMock, tags := newTestEC2InstancesInAvailabilityZones(t, []types.Instance{
+		newTestEC2InstanceInAvailabilityZone("us-east-1a", types.InstanceStateNameRunning),
+		newTestEC2InstanceInAvailabilityZone("us-east-1b", types.InstanceStateNameRunning),
+	})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstancesBalancedInAvailabilityZones(fakeTest, context.Background(), clientMock, tags, WithEC2InstancesMinimumAvailabilityZones(3))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestGetEC2InstancesByTagE_Paginated(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	filters := CreateFiltersFromMap(map[string][]string{"tag:service": {"api"}})
+	nextToken := "page2"
+	instanceID1 := "i-123456"
+	instanceID2 := "i-654321"
+	gomock.InOrder(
+		clientMock.EXPECT().
+			DescribeInstances(ctx, &ec2.DescribeInstancesInput{Filters: filters}).
+			Times(1).
+			Return(&ec2.DescribeInstancesOutput{
+				Reservations: []types.Reservation{{Instances: []types.Instance{{InstanceId: &instanceID1}}}},
+				NextToken:    &nextToken,
+			}, nil),
+		clientMock.EXPECT().
+			DescribeInstances(ctx, &ec2.DescribeInstancesInput{Filters: filters, NextToken: &nextToken}).
+			Times(1).
+			Return(&ec2.DescribeInstancesOutput{
+				Reservations: []types.Reservation{{Instances: []types.Instance{{InstanceId: &instanceID2}}}},
+			}, nil),
+	)
+
+	// Execute
+	actualOutput, err := GetEC2InstancesByTagE(ctx, clientMock, map[string][]string{"service": {"api"}})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []types.Instance{{InstanceId: &instanceID1}, {InstanceId: &instanceID2}}, actualOutput)
+}
+
+func TestGetEC2VolumesE_Paginated(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	nextToken := "page2"
+	volumeID1 := "vol-123456"
+	volumeID2 := "vol-654321"
+	gomock.InOrder(
+		clientMock.EXPECT().
+			DescribeVolumes(ctx, &ec2.DescribeVolumesInput{}).
+			Times(1).
+			Return(&ec2.DescribeVolumesOutput{Volumes: []types.Volume{{VolumeId: &volumeID1}}, NextToken: &nextToken}, nil),
+		clientMock.EXPECT().
+			DescribeVolumes(ctx, &ec2.DescribeVolumesInput{NextToken: &nextToken}).
+			Times(1).
+			Return(&ec2.DescribeVolumesOutput{Volumes: []types.Volume{{VolumeId: &volumeID2}}}, nil),
+	)
+
+	// Execute
+	actualOutput, err := getEC2VolumesE(ctx, clientMock, &ec2.DescribeVolumesInput{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []types.Volume{{VolumeId: &volumeID1}, {VolumeId: &volumeID2}}, actualOutput)
+}
+
+func TestGetEC2SecurityGroupByName_NotFound(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockEC2Client(ctrl)
+	filterName := "group-name"
+	input := &ec2.DescribeSecurityGroupsInput{
+		Filters: []types.Filter{
+			{
+				Name:   &filterName,
+				Values: []string{"missing"},
+			},
+		},
+	}
+	mockClient.EXPECT().DescribeSecurityGroups(ctx, input).Times(1).Return(&ec2.DescribeSecurityGroupsOutput{}, nil)
+
+	// Execute
+	actualOutput, err := GetEC2SecurityGroupByName(ctx, mockClient, "missing")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, actualOutput)
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeRouteTables(ctx context.Context, input *ec2.DescribeRouteTablesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeSubnets(ctx context.Context, input *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeVpcs(ctx context.Context, input *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeNetworkAcls(ctx context.Context, input *ec2.DescribeNetworkAclsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNetworkAclsOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeAddresses(ctx context.Context, input *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeInstanceAttribute(ctx context.Context, input *ec2.DescribeInstanceAttributeInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceAttributeOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeImages(ctx context.Context, input *ec2.DescribeImagesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeLaunchTemplateVersions(ctx context.Context, input *ec2.DescribeLaunchTemplateVersionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeVpcEndpoints(ctx context.Context, input *ec2.DescribeVpcEndpointsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcEndpointsOutput, error) {
+	return nil, nil
+}
+
+// This is a stub function; tests for this will use the new Mock object.
+func (c EC2ClientMock) DescribeFlowLogs(ctx context.Context, input *ec2.DescribeFlowLogsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeFlowLogsOutput, error) {
+	return nil, nil
+}
@@ -0,0 +1,270 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	volumeAttachmentInstanceIDFilterName string = "attachment.instance-id"
+	// volumeInstanceIDBatchSize is the number of instance IDs given in each request, within the limit EC2 places on filter values.
+	volumeInstanceIDBatchSize int = 200
+)
+
+// EC2ExpectedVolume describes the expected attributes of an EBS volume attached to an instance, for use with the AssertEC2InstanceVolumes
+// method. Any attribute that is left unset is not checked.
+type EC2ExpectedVolume struct {
+	// The volume type, e.g. gp3.
+	VolumeType types.VolumeType
+	// The size of the volume in GiB.
+	Size *int32
+	// The provisioned IOPS of the volume.
+	IOPS *int32
+	// The provisioned throughput of the volume in MiB/s.
+	Throughput *int32
+	// Whether the volume is encrypted.
+	Encrypted *bool
+	// The ID or ARN of the KMS key used to encrypt the volume, as returned by the EC2 API.
+	KMSKeyID string
+	// Whether the volume is deleted when the instance is terminated.
+	DeleteOnTermination *bool
+}
+
+/*
+AssertEC2InstanceVolumes asserts that the EBS volumes attached to an instance match a table of expected volumes, keyed by the device
+name they are mapped to. The instance and all its volumes are retrieved once, every expected attribute of every device is checked,
+and the test also fails if the instance has a device that is not in the table.
+
+# Examples
+
+Assert that an instance has exactly an encrypted 20 GiB root volume and a 100 GiB gp3 data volume.
+
+	AssertEC2InstanceVolumes(t, ctx, client, "i-0123456789abcdef0", map[string]EC2ExpectedVolume{
+		"/dev/xvda": {
+			Size:      aws.Int32(20),
+			Encrypted: aws.Bool(true),
+		},
+		"/dev/sdf": {
+			VolumeType:          types.VolumeTypeGp3,
+			Size:                aws.Int32(100),
+			DeleteOnTermination: aws.Bool(false),
+		},
+	})
+*/
+func AssertEC2InstanceVolumes(t *testing.T, ctx context.Context, client EC2Client, instanceID string, expectedVolumes map[string]EC2ExpectedVolume) {
+	instance, err := getEC2InstanceByInstanceIDE(ctx, client, instanceID)
+	require.NoError(t, err)
+
+	mappings := make(map[string]types.EbsInstanceBlockDevice)
+	var volumeIDs []string
+	for _, mapping := range instance.BlockDeviceMappings {
+		if mapping.DeviceName == nil || mapping.Ebs == nil || mapping.Ebs.VolumeId == nil {
+			continue
+		}
+		mappings[*mapping.DeviceName] = *mapping.Ebs
+		volumeIDs = append(volumeIDs, *mapping.Ebs.VolumeId)
+	}
+
+	volumes := make(map[string]types.Volume)
+	if len(volumeIDs) > 0 {
+		volumeList, err := getEC2VolumesE(ctx, client, &ec2.DescribeVolumesInput{VolumeIds: volumeIDs})
+		require.NoError(t, err)
+		for _, volume := range volumeList {
+			volumes[*volume.VolumeId] = volume
+		}
+	}
+
+	for deviceName := range mappings {
+		_, ok := expectedVolumes[deviceName]
+		assert.True(t, ok, "Instance '%s' has unexpected device '%s'.", instanceID, deviceName)
+	}
+
+	for deviceName, expected := range expectedVolumes {
+		mapping, ok := mappings[deviceName]
+		if !assert.True(t, ok, "Volume with device ID '%s' was not found for instance '%s'.", deviceName, instanceID) {
+			continue
+		}
+		if expected.DeleteOnTermination != nil {
+			assert.Equal(t, expected.DeleteOnTermination, mapping.DeleteOnTermination, "Volume with device ID '%s' does not have the right delete on termination setting.", deviceName)
+		}
+
+		volume, ok := volumes[*mapping.VolumeId]
+		if !assert.True(t, ok, "Volume '%s' with device ID '%s' could not be described.", *mapping.VolumeId, deviceName) {
+			continue
+		}
+		if expected.VolumeType != "" {
+			assert.Equal(t, expected.VolumeType, volume.VolumeType, "Volume with device ID '%s' does not have the right volume type.", deviceName)
+		}
+		if expected.Size != nil {
+			assert.Equal(t, expected.Size, volume.Size, "Volume with device ID '%s' does not have the right size.", deviceName)
+		}
+		if expected.IOPS != nil {
+			assert.Equal(t, expected.IOPS, volume.Iops, "Volume with device ID '%s' does not have the right IOPS value.", deviceName)
+		}
+		if expected.Throughput != nil {
+			assert.Equal(t, expected.Throughput, volume.Throughput, "Volume with device ID '%s' does not have the right throughput.", deviceName)
+		}
+		if expected.Encrypted != nil {
+			assert.Equal(t, expected.Encrypted, volume.Encrypted, "Volume with device ID '%s' does not have the right encryption setting.", deviceName)
+		}
+		if expected.KMSKeyID != "" {
+			assert.Equal(t, &expected.KMSKeyID, volume.KmsKeyId, "Volume with device ID '%s' was not encrypted using the correct KMS Key ID.", deviceName)
+		}
+	}
+}
+
+// AssertEC2VolumesEncryptedOptions is a struct used for functional options for the AssertEC2VolumesEncrypted method.
+type AssertEC2VolumesEncryptedOptions struct {
+	// Filters used to limit the volumes that are checked, in the format accepted by the CreateFiltersFromMap method.
+	Filters map[string][]string
+	// Tags used to limit the volumes that are checked to those attached to matching instances, in the format accepted by the
+	// GetEC2InstancesByTagE method.
+	AttachedInstanceTags map[string][]string
+	// The IDs or ARNs of the KMS keys that volumes may be encrypted with. If empty, any key is allowed.
+	AllowedKMSKeyIDs []string
+}
+
+// AssertEC2VolumesEncryptedOptsFunc is a type used for functional options for the AssertEC2VolumesEncrypted method.
+type AssertEC2VolumesEncryptedOptsFunc func(*AssertEC2VolumesEncryptedOptions) error
+
+// WithEC2VolumesFilters limits the volumes checked by the AssertEC2VolumesEncrypted method to those matching the given filters,
+// e.g. `map[string][]string{"availability-zone": {"us-east-1a"}, "tag:team": {"payments"}}`.
+func WithEC2VolumesFilters(filters map[string][]string) AssertEC2VolumesEncryptedOptsFunc {
+	return func(opts *AssertEC2VolumesEncryptedOptions) error {
+		opts.Filters = filters
+		return nil
+	}
+}
+
+// WithEC2VolumesAttachedInstanceTags limits the volumes checked by the AssertEC2VolumesEncrypted method to those attached to
+// instances with the given tags.
+func WithEC2VolumesAttachedInstanceTags(tags map[string][]string) AssertEC2VolumesEncryptedOptsFunc {
+	return func(opts *AssertEC2VolumesEncryptedOptions) error {
+		opts.AttachedInstanceTags = tags
+		return nil
+	}
+}
+
+// WithEC2VolumesAllowedKMSKeyIDs sets the IDs or ARNs of the KMS keys that volumes may be encrypted with.
+func WithEC2VolumesAllowedKMSKeyIDs(kmsKeyIDs ...string) AssertEC2VolumesEncryptedOptsFunc {
+	return func(opts *AssertEC2VolumesEncryptedOptions) error {
+		opts.AllowedKMSKeyIDs = append(opts.AllowedKMSKeyIDs, kmsKeyIDs...)
+		return nil
+	}
+}
+
+/*
+AssertEC2VolumesEncrypted asserts that every EBS volume visible to the client, optionally limited by functional options, is
+encrypted and (optionally) encrypted with one of a set of allowed KMS keys. The test failure lists every offending volume.
+Use the AssertEC2EBSEncryptionByDefaultEnabled method to also check that new volumes will be encrypted.
+
+# Examples
+
+Assert that all volumes attached to a service's instances are encrypted with the service's KMS key.
+
+	AssertEC2VolumesEncrypted(
+		t,
+		ctx,
+		client,
+		WithEC2VolumesAttachedInstanceTags(map[string][]string{"service": {"api"}}),
+		WithEC2VolumesAllowedKMSKeyIDs("arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"),
+	)
+*/
+func AssertEC2VolumesEncrypted(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertEC2VolumesEncryptedOptsFunc) {
+	opts := &AssertEC2VolumesEncryptedOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	var volumes []types.Volume
+	if len(opts.AttachedInstanceTags) > 0 {
+		instances, err := GetEC2InstancesByTagE(ctx, client, opts.AttachedInstanceTags)
+		require.NoError(t, err)
+		require.NotEmpty(t, instances, "No EC2 instances with the given tags were found.")
+		var instanceIDs []string
+		for _, instance := range instances {
+			instanceIDs = append(instanceIDs, *instance.InstanceId)
+		}
+		volumes, err = getEC2VolumesAttachedToInstancesE(ctx, client, opts.Filters, instanceIDs)
+		require.NoError(t, err)
+	} else {
+		var err error
+		volumes, err = getEC2VolumesE(ctx, client, &ec2.DescribeVolumesInput{Filters: CreateFiltersFromMap(opts.Filters)})
+		require.NoError(t, err)
+	}
+
+	var violations []string
+	for _, volume := range volumes {
+		if volume.Encrypted == nil || !*volume.Encrypted {
+			violations = append(violations, fmt.Sprintf("%s: not encrypted", *volume.VolumeId))
+			continue
+		}
+		if len(opts.AllowedKMSKeyIDs) > 0 && !kmsKeyIDAllowed(volume.KmsKeyId, opts.AllowedKMSKeyIDs) {
+			kmsKeyID := ""
+			if volume.KmsKeyId != nil {
+				kmsKeyID = *volume.KmsKeyId
+			}
+			violations = append(violations, fmt.Sprintf("%s: encrypted with KMS key '%s', which is not allowed", *volume.VolumeId, kmsKeyID))
+		}
+	}
+
+	assert.Empty(t, violations, "EBS volumes that are not correctly encrypted were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// AssertEC2EBSEncryptionByDefaultEnabled asserts that EBS encryption by default is enabled for the account and region of the client.
+func AssertEC2EBSEncryptionByDefaultEnabled(t *testing.T, ctx context.Context, client EC2Client) {
+	output, err := client.GetEbsEncryptionByDefault(ctx, &ec2.GetEbsEncryptionByDefaultInput{})
+	require.NoError(t, err)
+	assert.True(t, output.EbsEncryptionByDefault != nil && *output.EbsEncryptionByDefault, "EBS encryption by default is not enabled.")
+}
+
+// getEC2VolumesAttachedToInstancesE returns every volume matching the given filters that is attached to any of the given instances. The
+// instance IDs are sent in batches, as EC2 limits the number of values in a filter. A volume attached to instances in more than one
+// batch is returned once.
+func getEC2VolumesAttachedToInstancesE(ctx context.Context, client EC2Client, filters map[string][]string, instanceIDs []string) (volumes []types.Volume, err error) {
+	volumeIDs := make(map[string]bool)
+	for start := 0; start < len(instanceIDs); start += volumeInstanceIDBatchSize {
+		end := start + volumeInstanceIDBatchSize
+		if end > len(instanceIDs) {
+			end = len(instanceIDs)
+		}
+		batchFilters := append(CreateFiltersFromMap(filters), CreateFiltersFromMap(map[string][]string{volumeAttachmentInstanceIDFilterName: instanceIDs[start:end]})...)
+		batchVolumes, err := getEC2VolumesE(ctx, client, &ec2.DescribeVolumesInput{Filters: batchFilters})
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range batchVolumes {
+			if volume.VolumeId != nil {
+				if volumeIDs[*volume.VolumeId] {
+					continue
+				}
+				volumeIDs[*volume.VolumeId] = true
+			}
+			volumes = append(volumes, volume)
+		}
+	}
+	return
+}
+
+// kmsKeyIDAllowed returns true if a KMS key ARN, as returned by the EC2 API, matches any of a list of key IDs or ARNs.
+func kmsKeyIDAllowed(kmsKeyARN *string, allowedKMSKeyIDs []string) bool {
+	if kmsKeyARN == nil {
+		return false
+	}
+	for _, allowedKMSKeyID := range allowedKMSKeyIDs {
+		if *kmsKeyARN == allowedKMSKeyID || strings.HasSuffix(*kmsKeyARN, ":key/"+allowedKMSKeyID) {
+			return true
+		}
+	}
+	return false
+}
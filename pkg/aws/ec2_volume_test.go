@@ -0,0 +1,282 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEC2InstanceVolumesClient(t *testing.T) *mock.MockEC2Client {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	instanceOutput := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{
+					{
+						InstanceId: aws.String("i-123456"),
+						BlockDeviceMappings: []types.InstanceBlockDeviceMapping{
+							{
+								DeviceName: aws.String("/dev/xvda"),
+								Ebs: &types.EbsInstanceBlockDevice{
+									VolumeId:            aws.String("vol-root"),
+									DeleteOnTermination: aws.Bool(true),
+								},
+							},
+							{
+								DeviceName: aws.String("/dev/sdf"),
+								Ebs: &types.EbsInstanceBlockDevice{
+									VolumeId:            aws.String("vol-data"),
+									DeleteOnTermination: aws.Bool(false),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	volumeOutput := &ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{
+				VolumeId:   aws.String("vol-root"),
+				VolumeType: types.VolumeTypeGp3,
+				Size:       aws.Int32(20),
+				Iops:       aws.Int32(3000),
+				Throughput: aws.Int32(125),
+				Encrypted:  aws.Bool(true),
+				KmsKeyId:   aws.String("arn:aws:kms:us-east-1:123456789012:key/root"),
+			},
+			{
+				VolumeId:   aws.String("vol-data"),
+				VolumeType: types.VolumeTypeIo2,
+				Size:       aws.Int32(100),
+				Iops:       aws.Int32(5000),
+				Encrypted:  aws.Bool(true),
+				KmsKeyId:   aws.String("arn:aws:kms:us-east-1:123456789012:key/data"),
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{"i-123456"}}).Times(1).Return(instanceOutput, nil)
+	clientMock.EXPECT().DescribeVolumes(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{"vol-root", "vol-data"}}).Times(1).Return(volumeOutput, nil)
+	return clientMock
+}
+
+func TestAssertEC2InstanceVolumes_Match(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2InstanceVolumesClient(t)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceVolumes(fakeTest, context.Background(), clientMock, "i-123456", map[string]EC2ExpectedVolume{
+		"/dev/xvda": {
+			VolumeType:          types.VolumeTypeGp3,
+			Size:                aws.Int32(20),
+			IOPS:                aws.Int32(3000),
+			Throughput:          aws.Int32(125),
+			Encrypted:           aws.Bool(true),
+			KMSKeyID:            "arn:aws:kms:us-east-1:123456789012:key/root",
+			DeleteOnTermination: aws.Bool(true),
+		},
+		"/dev/sdf": {
+			VolumeType:          types.VolumeTypeIo2,
+			IOPS:                aws.Int32(5000),
+			DeleteOnTermination: aws.Bool(false),
+		},
+	})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceVolumes_NoMatch(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2InstanceVolumesClient(t)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceVolumes(fakeTest, context.Background(), clientMock, "i-123456", map[string]EC2ExpectedVolume{
+		"/dev/xvda": {
+			Size: aws.Int32(20),
+		},
+		"/dev/sdf": {
+			KMSKeyID: "arn:aws:kms:us-east-1:123456789012:key/root",
+		},
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceVolumes_UnexpectedDevice(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2InstanceVolumesClient(t)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceVolumes(fakeTest, context.Background(), clientMock, "i-123456", map[string]EC2ExpectedVolume{
+		"/dev/xvda": {},
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2InstanceVolumes_MissingDevice(t *testing.T) {
+	// Setup
+	clientMock := newTestEC2InstanceVolumesClient(t)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2InstanceVolumes(fakeTest, context.Background(), clientMock, "i-123456", map[string]EC2ExpectedVolume{
+		"/dev/xvda": {},
+		"/dev/sdf":  {},
+		"/dev/sdg":  {},
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func newTestEC2EncryptedVolumes() []types.Volume {
+	return []types.Volume{
+		{
+			VolumeId:  aws.String("vol-1"),
+			Encrypted: aws.Bool(true),
+			KmsKeyId:  aws.String("arn:aws:kms:us-east-1:123456789012:key/allowed"),
+		},
+		{
+			VolumeId:  aws.String("vol-2"),
+			Encrypted: aws.Bool(true),
+			KmsKeyId:  aws.String("arn:aws:kms:us-east-1:123456789012:key/other"),
+		},
+		{
+			VolumeId:  aws.String("vol-3"),
+			Encrypted: aws.Bool(false),
+		},
+	}
+}
+
+func TestAssertEC2VolumesEncrypted_AttachedInstanceTags(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	instanceOutput := &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{
+				Instances: []types.Instance{
+					{InstanceId: aws.String("i-123456")},
+				},
+			},
+		},
+	}
+	expectedVolumesInput := &ec2.DescribeVolumesInput{
+		Filters: CreateFiltersFromMap(map[string][]string{"attachment.instance-id": {"i-123456"}}),
+	}
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{Filters: CreateFiltersFromMap(map[string][]string{"tag:service": {"api"}})}).
+		Times(1).
+		Return(instanceOutput, nil)
+	clientMock.EXPECT().DescribeVolumes(ctx, expectedVolumesInput).Times(1).Return(&ec2.DescribeVolumesOutput{Volumes: newTestEC2EncryptedVolumes()[:1]}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2VolumesEncrypted(fakeTest, ctx, clientMock,
+		WithEC2VolumesAttachedInstanceTags(map[string][]string{"service": {"api"}}),
+		WithEC2VolumesAllowedKMSKeyIDs("allowed"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2VolumesEncrypted_BatchesAttachedInstanceIDs(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	var instances []types.Instance
+	var instanceIDs []string
+	for i := 0; i < 250; i++ {
+		instanceID := fmt.Sprintf("i-%06d", i)
+		instances = append(instances, types.Instance{InstanceId: aws.String(instanceID)})
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+	volumes := newTestEC2EncryptedVolumes()
+	clientMock.EXPECT().
+		DescribeInstances(ctx, &ec2.DescribeInstancesInput{Filters: CreateFiltersFromMap(map[string][]string{"tag:service": {"api"}})}).
+		Times(1).
+		Return(&ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: instances}}}, nil)
+	clientMock.EXPECT().
+		DescribeVolumes(ctx, &ec2.DescribeVolumesInput{Filters: CreateFiltersFromMap(map[string][]string{"attachment.instance-id": instanceIDs[:200]})}).
+		Times(1).
+		Return(&ec2.DescribeVolumesOutput{Volumes: volumes[:1]}, nil)
+	clientMock.EXPECT().
+		DescribeVolumes(ctx, &ec2.DescribeVolumesInput{Filters: CreateFiltersFromMap(map[string][]string{"attachment.instance-id": instanceIDs[200:]})}).
+		Times(1).
+		Return(&ec2.DescribeVolumesOutput{Volumes: volumes[2:]}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2VolumesEncrypted(fakeTest, ctx, clientMock, WithEC2VolumesAttachedInstanceTags(map[string][]string{"service": {"api"}}))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2VolumesEncrypted_Violations(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().DescribeVolumes(ctx, &ec2.DescribeVolumesInput{}).Times(1).Return(&ec2.DescribeVolumesOutput{Volumes: newTestEC2EncryptedVolumes()}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2VolumesEncrypted(fakeTest, ctx, clientMock, WithEC2VolumesAllowedKMSKeyIDs("arn:aws:kms:us-east-1:123456789012:key/allowed"))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertEC2EBSEncryptionByDefaultEnabled_Enabled(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().GetEbsEncryptionByDefault(ctx, &ec2.GetEbsEncryptionByDefaultInput{}).Times(1).Return(&ec2.GetEbsEncryptionByDefaultOutput{EbsEncryptionByDefault: aws.Bool(true)}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2EBSEncryptionByDefaultEnabled(fakeTest, ctx, clientMock)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertEC2EBSEncryptionByDefaultEnabled_Disabled(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().GetEbsEncryptionByDefault(ctx, &ec2.GetEbsEncryptionByDefaultInput{}).Times(1).Return(&ec2.GetEbsEncryptionByDefaultOutput{EbsEncryptionByDefault: aws.Bool(false)}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertEC2EBSEncryptionByDefaultEnabled(fakeTest, ctx, clientMock)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
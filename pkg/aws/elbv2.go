@@ -0,0 +1,372 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	elbv2AccessLogsEnabledAttribute string = "access_logs.s3.enabled"
+	elbv2RedirectProtocolHTTPS      string = "HTTPS"
+)
+
+type ELBV2Client interface {
+	DescribeLoadBalancers(context.Context, *elasticloadbalancingv2.DescribeLoadBalancersInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancersOutput, error)
+
+	DescribeLoadBalancerAttributes(context.Context, *elasticloadbalancingv2.DescribeLoadBalancerAttributesInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancerAttributesOutput, error)
+
+	DescribeListeners(context.Context, *elasticloadbalancingv2.DescribeListenersInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeListenersOutput, error)
+
+	DescribeRules(context.Context, *elasticloadbalancingv2.DescribeRulesInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeRulesOutput, error)
+
+	DescribeTargetHealth(context.Context, *elasticloadbalancingv2.DescribeTargetHealthInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeTargetHealthOutput, error)
+}
+
+// AssertELBV2TargetGroupHealthyOptions is a struct used for functional options for the AssertELBV2TargetGroupHealthy method.
+type AssertELBV2TargetGroupHealthyOptions struct {
+	// How long to wait for every target to become healthy. Defaults to zero, which checks the health of the targets only once.
+	Timeout time.Duration
+	// How long to wait between checks of target health. Defaults to 10 seconds. The last wait is shortened so that the health of the
+	// targets is checked once more when the timeout ends.
+	PollInterval time.Duration
+}
+
+// AssertELBV2TargetGroupHealthyOptsFunc is a type used for functional options for the AssertELBV2TargetGroupHealthy method.
+type AssertELBV2TargetGroupHealthyOptsFunc func(*AssertELBV2TargetGroupHealthyOptions) error
+
+// WithELBV2TargetHealthTimeout waits up to the given duration for every target in a target group to become healthy.
+func WithELBV2TargetHealthTimeout(timeout time.Duration) AssertELBV2TargetGroupHealthyOptsFunc {
+	return func(opts *AssertELBV2TargetGroupHealthyOptions) error {
+		if timeout < 0 {
+			return fmt.Errorf("target health timeout must not be negative, got %s", timeout)
+		}
+		opts.Timeout = timeout
+		return nil
+	}
+}
+
+// WithELBV2TargetHealthPollInterval sets how long to wait between checks of target health.
+func WithELBV2TargetHealthPollInterval(interval time.Duration) AssertELBV2TargetGroupHealthyOptsFunc {
+	return func(opts *AssertELBV2TargetGroupHealthyOptions) error {
+		if interval <= 0 {
+			return fmt.Errorf("target health poll interval must be positive, got %s", interval)
+		}
+		opts.PollInterval = interval
+		return nil
+	}
+}
+
+/*
+AssertELBV2Listeners asserts that a load balancer has exactly the expected listeners, given as a table of protocols keyed by port.
+
+# Examples
+
+Assert that an application load balancer listens for HTTP on port 80 and HTTPS on port 443, and nothing else.
+
+	AssertELBV2Listeners(t, ctx, client, loadBalancerARN, map[int32]elbv2types.ProtocolEnum{
+		80:  elbv2types.ProtocolEnumHttp,
+		443: elbv2types.ProtocolEnumHttps,
+	})
+*/
+func AssertELBV2Listeners(t *testing.T, ctx context.Context, client ELBV2Client, loadBalancerARN string, expected map[int32]elbv2types.ProtocolEnum) {
+	listeners, err := getELBV2ListenersE(ctx, client, loadBalancerARN)
+	require.NoError(t, err)
+
+	actual := make(map[int32]elbv2types.ProtocolEnum)
+	for _, listener := range listeners {
+		if listener.Port != nil {
+			actual[*listener.Port] = listener.Protocol
+		}
+	}
+	assert.Equal(t, expected, actual, "Load balancer '%s' does not have the expected listeners.", loadBalancerARN)
+}
+
+/*
+AssertELBV2ListenersTLSPolicy asserts that every HTTPS and TLS listener of a load balancer uses one of the allowed security
+policies. The test failure lists every listener that does not.
+
+# Examples
+
+Assert that a load balancer only negotiates TLS 1.3.
+
+	AssertELBV2ListenersTLSPolicy(t, ctx, client, loadBalancerARN, "ELBSecurityPolicy-TLS13-1-3-2021-06")
+*/
+func AssertELBV2ListenersTLSPolicy(t *testing.T, ctx context.Context, client ELBV2Client, loadBalancerARN string, allowedPolicies ...string) {
+	listeners, err := getELBV2ListenersE(ctx, client, loadBalancerARN)
+	require.NoError(t, err)
+
+	var violations []string
+	for _, listener := range listeners {
+		if listener.Protocol != elbv2types.ProtocolEnumHttps && listener.Protocol != elbv2types.ProtocolEnumTls {
+			continue
+		}
+		policy := ""
+		if listener.SslPolicy != nil {
+			policy = *listener.SslPolicy
+		}
+		if !stringInSlice(policy, allowedPolicies) {
+			violations = append(violations, fmt.Sprintf("%s listener on port %d uses policy '%s'", listener.Protocol, getELBV2ListenerPort(listener), policy))
+		}
+	}
+
+	assert.Empty(t, violations, "Load balancer '%s' has listeners that do not use an allowed TLS policy:\n%s", loadBalancerARN, strings.Join(violations, "\n"))
+}
+
+// AssertELBV2ListenerRedirectsToHTTPS asserts that the HTTP listener of a load balancer on the given port redirects every request
+// to HTTPS, i.e. that the default action and the actions of every other rule are redirects to HTTPS.
+func AssertELBV2ListenerRedirectsToHTTPS(t *testing.T, ctx context.Context, client ELBV2Client, loadBalancerARN string, port int32) {
+	listeners, err := getELBV2ListenersE(ctx, client, loadBalancerARN)
+	require.NoError(t, err)
+
+	var listener *elbv2types.Listener
+	for i := range listeners {
+		if getELBV2ListenerPort(listeners[i]) == port {
+			listener = &listeners[i]
+			break
+		}
+	}
+	require.NotNil(t, listener, "Load balancer '%s' does not have a listener on port %d.", loadBalancerARN, port)
+	if !assert.Equal(t, elbv2types.ProtocolEnumHttp, listener.Protocol, "Listener on port %d of load balancer '%s' is not an HTTP listener.", port, loadBalancerARN) {
+		return
+	}
+
+	rules, err := getELBV2RulesE(ctx, client, *listener.ListenerArn)
+	require.NoError(t, err)
+
+	var violations []string
+	for _, rule := range rules {
+		if !elbv2ActionsRedirectToHTTPS(rule.Actions) {
+			priority := ""
+			if rule.Priority != nil {
+				priority = *rule.Priority
+			}
+			violations = append(violations, fmt.Sprintf("rule with priority '%s'", priority))
+		}
+	}
+
+	assert.Empty(t, violations, "Listener on port %d of load balancer '%s' has rules that do not redirect to HTTPS:\n%s", port, loadBalancerARN, strings.Join(violations, "\n"))
+}
+
+/*
+AssertELBV2TargetGroupHealthy asserts that a target group has at least one target and that every target is healthy. By default the
+health of the targets is checked once; use WithELBV2TargetHealthTimeout to wait for targets that are still registering.
+
+# Examples
+
+Wait up to five minutes for every target to become healthy after a deployment.
+
+	AssertELBV2TargetGroupHealthy(t, ctx, client, targetGroupARN, WithELBV2TargetHealthTimeout(5*time.Minute))
+*/
+func AssertELBV2TargetGroupHealthy(t *testing.T, ctx context.Context, client ELBV2Client, targetGroupARN string, optFns ...AssertELBV2TargetGroupHealthyOptsFunc) {
+	opts := &AssertELBV2TargetGroupHealthyOptions{
+		PollInterval: 10 * time.Second,
+	}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	deadline := time.Now().Add(opts.Timeout)
+	var violations []string
+poll:
+	for {
+		descriptions, err := getELBV2TargetHealthE(ctx, client, targetGroupARN)
+		require.NoError(t, err)
+		violations = getELBV2TargetHealthViolations(descriptions)
+		remaining := time.Until(deadline)
+		if len(violations) == 0 || remaining <= 0 {
+			break
+		}
+		// Never wait past the deadline, so that the last check happens when the timeout ends.
+		wait := opts.PollInterval
+		if remaining < wait {
+			wait = remaining
+		}
+		t.Logf("Target group %s has unhealthy targets, retrying in %s", targetGroupARN, wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			t.Logf("Stopped waiting for target group %s: %s", targetGroupARN, ctx.Err())
+			break poll
+		case <-timer.C:
+		}
+	}
+
+	assert.Empty(t, violations, "Target group '%s' has unhealthy targets:\n%s", targetGroupARN, strings.Join(violations, "\n"))
+}
+
+// AssertELBV2LoadBalancerAccessLogsEnabled asserts that access logging is enabled for a load balancer.
+func AssertELBV2LoadBalancerAccessLogsEnabled(t *testing.T, ctx context.Context, client ELBV2Client, loadBalancerARN string) {
+	input := &elasticloadbalancingv2.DescribeLoadBalancerAttributesInput{
+		LoadBalancerArn: &loadBalancerARN,
+	}
+	output, err := client.DescribeLoadBalancerAttributes(ctx, input)
+	require.NoError(t, err)
+
+	enabled := ""
+	for _, attribute := range output.Attributes {
+		if attribute.Key != nil && *attribute.Key == elbv2AccessLogsEnabledAttribute && attribute.Value != nil {
+			enabled = *attribute.Value
+		}
+	}
+	assert.Equal(t, "true", enabled, "Load balancer '%s' does not have access logging enabled.", loadBalancerARN)
+}
+
+// AssertELBV2LoadBalancerScheme asserts that a load balancer has the expected scheme, either internal or internet-facing.
+func AssertELBV2LoadBalancerScheme(t *testing.T, ctx context.Context, client ELBV2Client, loadBalancerARN string, scheme elbv2types.LoadBalancerSchemeEnum) {
+	loadBalancer, err := getELBV2LoadBalancerByARNE(ctx, client, loadBalancerARN)
+	require.NoError(t, err)
+
+	assert.Equal(t, scheme, loadBalancer.Scheme, "Load balancer '%s' does not have the right scheme.", loadBalancerARN)
+}
+
+// AssertELBV2LoadBalancerSecurityGroups asserts that exactly the given security groups are attached to a load balancer.
+func AssertELBV2LoadBalancerSecurityGroups(t *testing.T, ctx context.Context, client ELBV2Client, loadBalancerARN string, securityGroupIDs []string) {
+	loadBalancer, err := getELBV2LoadBalancerByARNE(ctx, client, loadBalancerARN)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, securityGroupIDs, loadBalancer.SecurityGroups, "Load balancer '%s' does not have the right security groups attached.", loadBalancerARN)
+}
+
+// elbv2ActionsRedirectToHTTPS returns true if a set of rule actions ends in a redirect to HTTPS.
+func elbv2ActionsRedirectToHTTPS(actions []elbv2types.Action) bool {
+	for _, action := range actions {
+		if action.Type != elbv2types.ActionTypeEnumRedirect {
+			continue
+		}
+		if action.RedirectConfig != nil && action.RedirectConfig.Protocol != nil && *action.RedirectConfig.Protocol == elbv2RedirectProtocolHTTPS {
+			return true
+		}
+	}
+	return false
+}
+
+// getELBV2TargetHealthViolations returns a description of every target that is not healthy, in a stable order, or a single
+// violation if there are no targets at all.
+func getELBV2TargetHealthViolations(descriptions []elbv2types.TargetHealthDescription) (violations []string) {
+	if len(descriptions) == 0 {
+		return []string{"no targets are registered"}
+	}
+	for _, description := range descriptions {
+		state := elbv2types.TargetHealthStateEnum("")
+		reason := ""
+		if description.TargetHealth != nil {
+			state = description.TargetHealth.State
+			reason = string(description.TargetHealth.Reason)
+		}
+		if state == elbv2types.TargetHealthStateEnumHealthy {
+			continue
+		}
+		targetID := ""
+		if description.Target != nil && description.Target.Id != nil {
+			targetID = *description.Target.Id
+		}
+		violation := fmt.Sprintf("%s: %s", targetID, state)
+		if reason != "" {
+			violation = fmt.Sprintf("%s (%s)", violation, reason)
+		}
+		violations = append(violations, violation)
+	}
+	sort.Strings(violations)
+	return
+}
+
+// getELBV2ListenerPort returns the port of a listener, or zero if it is not set.
+func getELBV2ListenerPort(listener elbv2types.Listener) int32 {
+	if listener.Port == nil {
+		return 0
+	}
+	return *listener.Port
+}
+
+// getELBV2LoadBalancerByARNE returns the load balancer with the given ARN.
+func getELBV2LoadBalancerByARNE(ctx context.Context, client ELBV2Client, loadBalancerARN string) (elbv2types.LoadBalancer, error) {
+	input := &elasticloadbalancingv2.DescribeLoadBalancersInput{
+		LoadBalancerArns: []string{loadBalancerARN},
+	}
+	output, err := client.DescribeLoadBalancers(ctx, input)
+	if err != nil {
+		return elbv2types.LoadBalancer{}, err
+	}
+	if len(output.LoadBalancers) == 0 {
+		return elbv2types.LoadBalancer{}, fmt.Errorf("load balancer with ARN '%s' was not found", loadBalancerARN)
+	}
+	return output.LoadBalancers[0], nil
+}
+
+// getELBV2ListenersE returns every listener of a load balancer.
+func getELBV2ListenersE(ctx context.Context, client ELBV2Client, loadBalancerARN string) ([]elbv2types.Listener, error) {
+	input := &elasticloadbalancingv2.DescribeListenersInput{
+		LoadBalancerArn: &loadBalancerARN,
+	}
+	return collectPagesE(ctx, elasticloadbalancingv2.NewDescribeListenersPaginator(client, input), func(output *elasticloadbalancingv2.DescribeListenersOutput) []elbv2types.Listener {
+		return output.Listeners
+	})
+}
+
+// elbv2DescribeRulesPaginator reads every page of a DescribeRules request, for which the SDK does not provide a paginator.
+type elbv2DescribeRulesPaginator struct {
+	client    ELBV2Client
+	input     elasticloadbalancingv2.DescribeRulesInput
+	firstPage bool
+}
+
+// newELBV2DescribeRulesPaginator returns a paginator for the rules matching the given input.
+func newELBV2DescribeRulesPaginator(client ELBV2Client, input *elasticloadbalancingv2.DescribeRulesInput) *elbv2DescribeRulesPaginator {
+	return &elbv2DescribeRulesPaginator{
+		client:    client,
+		input:     *input,
+		firstPage: true,
+	}
+}
+
+// HasMorePages returns true if there are more pages of rules to read.
+func (p *elbv2DescribeRulesPaginator) HasMorePages() bool {
+	return p.firstPage || (p.input.Marker != nil && *p.input.Marker != "")
+}
+
+// NextPage reads the next page of rules.
+func (p *elbv2DescribeRulesPaginator) NextPage(ctx context.Context, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeRulesOutput, error) {
+	input := p.input
+	output, err := p.client.DescribeRules(ctx, &input, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	p.firstPage = false
+	p.input.Marker = output.NextMarker
+	return output, nil
+}
+
+// getELBV2RulesE returns every rule of a listener, including the default rule.
+func getELBV2RulesE(ctx context.Context, client ELBV2Client, listenerARN string) ([]elbv2types.Rule, error) {
+	input := &elasticloadbalancingv2.DescribeRulesInput{
+		ListenerArn: &listenerARN,
+	}
+	return collectPagesE(ctx, newELBV2DescribeRulesPaginator(client, input), func(output *elasticloadbalancingv2.DescribeRulesOutput) []elbv2types.Rule {
+		return output.Rules
+	})
+}
+
+// getELBV2TargetHealthE returns the health of every target in a target group.
+func getELBV2TargetHealthE(ctx context.Context, client ELBV2Client, targetGroupARN string) ([]elbv2types.TargetHealthDescription, error) {
+	input := &elasticloadbalancingv2.DescribeTargetHealthInput{
+		TargetGroupArn: &targetGroupARN,
+	}
+	output, err := client.DescribeTargetHealth(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.TargetHealthDescriptions, nil
+}
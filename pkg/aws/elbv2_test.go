@@ -0,0 +1,474 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testELBV2LoadBalancerARN string = "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/api/0123456789abcdef"
+	testELBV2ListenerARN     string = "arn:aws:elasticloadbalancing:us-east-1:123456789012:listener/app/api/0123456789abcdef/0123456789abcdef"
+	testELBV2TargetGroupARN  string = "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/api/0123456789abcdef"
+)
+
+func newTestELBV2TargetHealthDescription(id string, state elbv2types.TargetHealthStateEnum) elbv2types.TargetHealthDescription {
+	return elbv2types.TargetHealthDescription{
+		Target:       &elbv2types.TargetDescription{Id: aws.String(id)},
+		TargetHealth: &elbv2types.TargetHealth{State: state},
+	}
+}
+
+func TestAssertELBV2Listeners_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+		}, gomock.Any()).
+		Times(1).
+		Return(&elasticloadbalancingv2.DescribeListenersOutput{
+			Listeners: []elbv2types.Listener{
+				{
+					ListenerArn: aws.String(testELBV2ListenerARN),
+					Port:        aws.Int32(80),
+					Protocol:    elbv2types.ProtocolEnumHttp,
+				},
+				{
+					Port:      aws.Int32(443),
+					Protocol:  elbv2types.ProtocolEnumHttps,
+					SslPolicy: aws.String("ELBSecurityPolicy-TLS13-1-2-2021-06"),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2Listeners(fakeTest, ctx, clientMock, testELBV2LoadBalancerARN, map[int32]elbv2types.ProtocolEnum{
+		80:  elbv2types.ProtocolEnumHttp,
+		443: elbv2types.ProtocolEnumHttps,
+	})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2Listeners_NoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+		}, gomock.Any()).
+		Times(1).
+		Return(&elasticloadbalancingv2.DescribeListenersOutput{
+			Listeners: []elbv2types.Listener{
+				{
+					ListenerArn: aws.String(testELBV2ListenerARN),
+					Port:        aws.Int32(80),
+					Protocol:    elbv2types.ProtocolEnumHttp,
+				},
+				{
+					Port:      aws.Int32(443),
+					Protocol:  elbv2types.ProtocolEnumHttps,
+					SslPolicy: aws.String("ELBSecurityPolicy-TLS13-1-2-2021-06"),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2Listeners(fakeTest, ctx, clientMock, testELBV2LoadBalancerARN, map[int32]elbv2types.ProtocolEnum{
+		443: elbv2types.ProtocolEnumHttps,
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2ListenersTLSPolicy_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+		}, gomock.Any()).
+		Times(1).
+		Return(&elasticloadbalancingv2.DescribeListenersOutput{
+			Listeners: []elbv2types.Listener{
+				{
+					ListenerArn: aws.String(testELBV2ListenerARN),
+					Port:        aws.Int32(80),
+					Protocol:    elbv2types.ProtocolEnumHttp,
+				},
+				{
+					Port:      aws.Int32(443),
+					Protocol:  elbv2types.ProtocolEnumHttps,
+					SslPolicy: aws.String("ELBSecurityPolicy-TLS13-1-2-2021-06"),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2ListenersTLSPolicy(fakeTest, ctx, clientMock, testELBV2LoadBalancerARN, "ELBSecurityPolicy-TLS13-1-2-2021-06")
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2ListenersTLSPolicy_NoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+		}, gomock.Any()).
+		Times(1).
+		Return(&elasticloadbalancingv2.DescribeListenersOutput{
+			Listeners: []elbv2types.Listener{
+				{
+					ListenerArn: aws.String(testELBV2ListenerARN),
+					Port:        aws.Int32(80),
+					Protocol:    elbv2types.ProtocolEnumHttp,
+				},
+				{
+					Port:      aws.Int32(443),
+					Protocol:  elbv2types.ProtocolEnumHttps,
+					SslPolicy: aws.String("ELBSecurityPolicy-TLS13-1-2-2021-06"),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2ListenersTLSPolicy(fakeTest, ctx, clientMock, testELBV2LoadBalancerARN, "ELBSecurityPolicy-TLS13-1-3-2021-06")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2ListenerRedirectsToHTTPS_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+		}, gomock.Any()).
+		Times(1).
+		Return(&elasticloadbalancingv2.DescribeListenersOutput{
+			Listeners: []elbv2types.Listener{
+				{
+					ListenerArn: aws.String(testELBV2ListenerARN),
+					Port:        aws.Int32(80),
+					Protocol:    elbv2types.ProtocolEnumHttp,
+				},
+				{
+					Port:      aws.Int32(443),
+					Protocol:  elbv2types.ProtocolEnumHttps,
+					SslPolicy: aws.String("ELBSecurityPolicy-TLS13-1-2-2021-06"),
+				},
+			},
+		}, nil)
+	input := &elasticloadbalancingv2.DescribeRulesInput{
+		ListenerArn: aws.String(testELBV2ListenerARN),
+	}
+	output := &elasticloadbalancingv2.DescribeRulesOutput{
+		Rules: []elbv2types.Rule{
+			{
+				IsDefault: aws.Bool(true),
+				Priority:  aws.String("default"),
+				Actions: []elbv2types.Action{
+					{
+						Type: elbv2types.ActionTypeEnumRedirect,
+						RedirectConfig: &elbv2types.RedirectActionConfig{
+							Protocol:   aws.String("HTTPS"),
+							Port:       aws.String("443"),
+							StatusCode: elbv2types.RedirectActionStatusCodeEnumHttp301,
+						},
+					},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeRules(ctx, input).Times(1).Return(output, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2ListenerRedirectsToHTTPS(fakeTest, ctx, clientMock, testELBV2LoadBalancerARN, 80)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2ListenerRedirectsToHTTPS_NoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+		}, gomock.Any()).
+		Times(1).
+		Return(&elasticloadbalancingv2.DescribeListenersOutput{
+			Listeners: []elbv2types.Listener{
+				{
+					ListenerArn: aws.String(testELBV2ListenerARN),
+					Port:        aws.Int32(80),
+					Protocol:    elbv2types.ProtocolEnumHttp,
+				},
+				{
+					Port:      aws.Int32(443),
+					Protocol:  elbv2types.ProtocolEnumHttps,
+					SslPolicy: aws.String("ELBSecurityPolicy-TLS13-1-2-2021-06"),
+				},
+			},
+		}, nil)
+	input := &elasticloadbalancingv2.DescribeRulesInput{
+		ListenerArn: aws.String(testELBV2ListenerARN),
+	}
+	output := &elasticloadbalancingv2.DescribeRulesOutput{
+		Rules: []elbv2types.Rule{
+			{
+				IsDefault: aws.Bool(true),
+				Priority:  aws.String("default"),
+				Actions: []elbv2types.Action{
+					{
+						Type:           elbv2types.ActionTypeEnumForward,
+						TargetGroupArn: aws.String(testELBV2TargetGroupARN),
+					},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeRules(ctx, input).Times(1).Return(output, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2ListenerRedirectsToHTTPS(fakeTest, ctx, clientMock, testELBV2LoadBalancerARN, 80)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2ListenerRedirectsToHTTPS_NotHTTPListener(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+		}, gomock.Any()).
+		Times(1).
+		Return(&elasticloadbalancingv2.DescribeListenersOutput{
+			Listeners: []elbv2types.Listener{
+				{
+					ListenerArn: aws.String(testELBV2ListenerARN),
+					Port:        aws.Int32(80),
+					Protocol:    elbv2types.ProtocolEnumHttp,
+				},
+				{
+					Port:      aws.Int32(443),
+					Protocol:  elbv2types.ProtocolEnumHttps,
+					SslPolicy: aws.String("ELBSecurityPolicy-TLS13-1-2-2021-06"),
+				},
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2ListenerRedirectsToHTTPS(fakeTest, ctx, clientMock, testELBV2LoadBalancerARN, 443)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2TargetGroupHealthy_BecomesHealthy(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	input := &elasticloadbalancingv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(testELBV2TargetGroupARN),
+	}
+	gomock.InOrder(
+		clientMock.EXPECT().DescribeTargetHealth(context.Background(), input).Times(1).Return(&elasticloadbalancingv2.DescribeTargetHealthOutput{
+			TargetHealthDescriptions: []elbv2types.TargetHealthDescription{
+				newTestELBV2TargetHealthDescription("i-123456", elbv2types.TargetHealthStateEnumHealthy),
+				newTestELBV2TargetHealthDescription("i-654321", elbv2types.TargetHealthStateEnumInitial),
+			},
+		}, nil),
+		clientMock.EXPECT().DescribeTargetHealth(context.Background(), input).Times(1).Return(&elasticloadbalancingv2.DescribeTargetHealthOutput{
+			TargetHealthDescriptions: []elbv2types.TargetHealthDescription{
+				newTestELBV2TargetHealthDescription("i-123456", elbv2types.TargetHealthStateEnumHealthy),
+				newTestELBV2TargetHealthDescription("i-654321", elbv2types.TargetHealthStateEnumHealthy),
+			},
+		}, nil),
+	)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2TargetGroupHealthy(fakeTest, context.Background(), clientMock, testELBV2TargetGroupARN,
+		WithELBV2TargetHealthTimeout(time.Minute),
+		WithELBV2TargetHealthPollInterval(time.Millisecond),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2TargetGroupHealthy_TimeoutShorterThanPollInterval(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	input := &elasticloadbalancingv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(testELBV2TargetGroupARN),
+	}
+	gomock.InOrder(
+		clientMock.EXPECT().DescribeTargetHealth(context.Background(), input).Times(1).Return(&elasticloadbalancingv2.DescribeTargetHealthOutput{
+			TargetHealthDescriptions: []elbv2types.TargetHealthDescription{
+				newTestELBV2TargetHealthDescription("i-123456", elbv2types.TargetHealthStateEnumInitial),
+			},
+		}, nil),
+		clientMock.EXPECT().DescribeTargetHealth(context.Background(), input).Times(1).Return(&elasticloadbalancingv2.DescribeTargetHealthOutput{
+			TargetHealthDescriptions: []elbv2types.TargetHealthDescription{
+				newTestELBV2TargetHealthDescription("i-123456", elbv2types.TargetHealthStateEnumHealthy),
+			},
+		}, nil),
+	)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2TargetGroupHealthy(fakeTest, context.Background(), clientMock, testELBV2TargetGroupARN,
+		WithELBV2TargetHealthTimeout(50*time.Millisecond),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2TargetGroupHealthy_Unhealthy(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	input := &elasticloadbalancingv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(testELBV2TargetGroupARN),
+	}
+	clientMock.EXPECT().DescribeTargetHealth(context.Background(), input).Times(1).Return(&elasticloadbalancingv2.DescribeTargetHealthOutput{
+		TargetHealthDescriptions: []elbv2types.TargetHealthDescription{
+			newTestELBV2TargetHealthDescription("i-123456", elbv2types.TargetHealthStateEnumUnhealthy),
+		},
+	}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2TargetGroupHealthy(fakeTest, context.Background(), clientMock, testELBV2TargetGroupARN)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestGetELBV2TargetHealthViolations_NoTargets(t *testing.T) {
+	// Execute
+	violations := getELBV2TargetHealthViolations(nil)
+
+	// Assert
+	assert.Len(t, violations, 1)
+}
+
+func TestAssertELBV2LoadBalancerAccessLogsEnabled_NoMatch(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	input := &elasticloadbalancingv2.DescribeLoadBalancerAttributesInput{
+		LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+	}
+	clientMock.EXPECT().DescribeLoadBalancerAttributes(context.Background(), input).Times(1).Return(&elasticloadbalancingv2.DescribeLoadBalancerAttributesOutput{
+		Attributes: []elbv2types.LoadBalancerAttribute{
+			{Key: aws.String("access_logs.s3.enabled"), Value: aws.String("false")},
+		},
+	}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2LoadBalancerAccessLogsEnabled(fakeTest, context.Background(), clientMock, testELBV2LoadBalancerARN)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2LoadBalancerSchemeAndSecurityGroups_Match(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	input := &elasticloadbalancingv2.DescribeLoadBalancersInput{
+		LoadBalancerArns: []string{testELBV2LoadBalancerARN},
+	}
+	clientMock.EXPECT().DescribeLoadBalancers(context.Background(), input).Times(2).Return(&elasticloadbalancingv2.DescribeLoadBalancersOutput{
+		LoadBalancers: []elbv2types.LoadBalancer{
+			{
+				LoadBalancerArn: aws.String(testELBV2LoadBalancerARN),
+				Scheme:          elbv2types.LoadBalancerSchemeEnumInternal,
+				SecurityGroups:  []string{"sg-123456", "sg-654321"},
+			},
+		},
+	}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2LoadBalancerScheme(fakeTest, context.Background(), clientMock, testELBV2LoadBalancerARN, elbv2types.LoadBalancerSchemeEnumInternal)
+	AssertELBV2LoadBalancerSecurityGroups(fakeTest, context.Background(), clientMock, testELBV2LoadBalancerARN, []string{"sg-654321", "sg-123456"})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertELBV2TargetGroupHealthy_ContextCancelled(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockELBV2Client(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	input := &elasticloadbalancingv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(testELBV2TargetGroupARN),
+	}
+	clientMock.EXPECT().DescribeTargetHealth(ctx, input).Times(1).DoAndReturn(
+		func(context.Context, *elasticloadbalancingv2.DescribeTargetHealthInput, ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeTargetHealthOutput, error) {
+			cancel()
+			return &elasticloadbalancingv2.DescribeTargetHealthOutput{
+				TargetHealthDescriptions: []elbv2types.TargetHealthDescription{
+					newTestELBV2TargetHealthDescription("i-123456", elbv2types.TargetHealthStateEnumInitial),
+				},
+			}, nil
+		})
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertELBV2TargetGroupHealthy(fakeTest, ctx, clientMock, testELBV2TargetGroupARN,
+		WithELBV2TargetHealthTimeout(time.Hour),
+		WithELBV2TargetHealthPollInterval(time.Minute),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
@@ -4,11 +4,16 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/square/go-jose.v2/json"
 
 	"testing"
@@ -17,6 +22,16 @@ import (
 // IAMClient serves as a stub client interface for the AWS SDK [IAM client](https://pkg.go.dev/github.com/aws/aws-sdk-go/service/iam#hdr-Using_the_Client).
 type IAMClient interface {
 	GetRole(context.Context, *iam.GetRoleInput, ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+
+	ListRolePolicies(context.Context, *iam.ListRolePoliciesInput, ...func(*iam.Options)) (*iam.ListRolePoliciesOutput, error)
+
+	GetRolePolicy(context.Context, *iam.GetRolePolicyInput, ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error)
+
+	ListAttachedRolePolicies(context.Context, *iam.ListAttachedRolePoliciesInput, ...func(*iam.Options)) (*iam.ListAttachedRolePoliciesOutput, error)
+
+	GetPolicy(context.Context, *iam.GetPolicyInput, ...func(*iam.Options)) (*iam.GetPolicyOutput, error)
+
+	GetPolicyVersion(context.Context, *iam.GetPolicyVersionInput, ...func(*iam.Options)) (*iam.GetPolicyVersionOutput, error)
 }
 
 type PolicyDocument struct {
@@ -25,9 +40,16 @@ type PolicyDocument struct {
 }
 
 type StatementEntry struct {
-	Effect   string
-	Action   interface{}
-	Resource interface{}
+	Sid          string
+	Effect       string
+	Principal    interface{}
+	NotPrincipal interface{}
+	Action       interface{}
+	NotAction    interface{}
+	Resource     interface{}
+	NotResource  interface{}
+	// Condition maps each condition operator, e.g. "StringEquals", to the condition keys and values it is applied to.
+	Condition map[string]map[string]interface{}
 }
 
 // AssertIAMPolicyDocumentContainsResourceAction will assert the an IAM Policy Document provided contains a Statement with the given Resource, Action, and Effect.
@@ -72,7 +94,7 @@ func checkIAMPolicyDocumentContainsResourceAction(resource string, action string
 }
 
 // getIAMPolicyDefaultVersionE gets the current version of the IAM Policy for a given ARN.
-func getIAMPolicyDefaultVersionE(context context.Context, policyArn string, client *iam.Client) (PolicyDocument, error) {
+func getIAMPolicyDefaultVersionE(context context.Context, policyArn string, client IAMClient) (PolicyDocument, error) {
 	IAMGetPolicyInput := &iam.GetPolicyInput{
 		PolicyArn: &policyArn,
 	}
@@ -116,7 +138,7 @@ func getIAMRolePolicyNamesE(context context.Context, client iam.ListRolePolicies
 }
 
 // getIAMRolePolicyDocuments returns an array of PolicyDocument structs representing all the inline IAM Policies attached to a role.
-func getIAMRolePolicyDocuments(context context.Context, client *iam.Client, roleName string, policyNames []string) ([]PolicyDocument, error) {
+func getIAMRolePolicyDocuments(context context.Context, client IAMClient, roleName string, policyNames []string) ([]PolicyDocument, error) {
 	rolePolicyDocuments := []PolicyDocument{}
 
 	for i := 0; i < len(policyNames); i++ {
@@ -138,6 +160,45 @@ func getIAMRolePolicyDocuments(context context.Context, client *iam.Client, role
 	return rolePolicyDocuments, nil
 }
 
+// getIAMRoleAttachedPolicyARNsE returns an array of strings containing the ARNs of all managed policies attached to a Role.
+func getIAMRoleAttachedPolicyARNsE(ctx context.Context, client IAMClient, roleName string) ([]string, error) {
+	input := &iam.ListAttachedRolePoliciesInput{
+		RoleName: &roleName,
+	}
+	return collectPagesE(ctx, iam.NewListAttachedRolePoliciesPaginator(client, input), func(output *iam.ListAttachedRolePoliciesOutput) (policyARNs []string) {
+		for _, attachedPolicy := range output.AttachedPolicies {
+			policyARNs = append(policyARNs, *attachedPolicy.PolicyArn)
+		}
+		return
+	})
+}
+
+// getIAMRoleEffectivePolicyDocumentsE returns an array of PolicyDocument structs representing all the inline IAM Policies of a Role,
+// followed by the default versions of all the managed IAM Policies attached to it.
+func getIAMRoleEffectivePolicyDocumentsE(ctx context.Context, client IAMClient, roleName string) ([]PolicyDocument, error) {
+	policyNames, err := getIAMRolePolicyNamesE(ctx, client, roleName)
+	if err != nil {
+		return nil, err
+	}
+	policyDocuments, err := getIAMRolePolicyDocuments(ctx, client, roleName, policyNames)
+	if err != nil {
+		return nil, err
+	}
+
+	policyARNs, err := getIAMRoleAttachedPolicyARNsE(ctx, client, roleName)
+	if err != nil {
+		return nil, err
+	}
+	for _, policyARN := range policyARNs {
+		policyDocument, err := getIAMPolicyDefaultVersionE(ctx, policyARN, client)
+		if err != nil {
+			return nil, err
+		}
+		policyDocuments = append(policyDocuments, policyDocument)
+	}
+	return policyDocuments, nil
+}
+
 // findIamPolicyAction returns the index of a particular Action in an IAM Policy Document Statement. If the Action is not found, it will
 // return -1.
 func findIamPolicyAction(statement StatementEntry, action string, effect string) int {
@@ -171,6 +232,9 @@ func findIAMPolicyResource(statements []StatementEntry, resource string, startIn
 func parseIAMPolicyField(field interface{}) []string {
 	var array []string
 
+	if field == nil {
+		return array
+	}
 	if reflect.TypeOf(field).String() != "string" {
 		intArray := field.([]interface{})
 		strArray := make([]string, len(intArray))
@@ -223,3 +287,176 @@ func AssertIAMRoleMaxSessionDuration(t *testing.T, ctx context.Context, client I
 	assert.Equal(t, *output.Role.MaxSessionDuration, maxDuration, "Role %s Has an incorrect max session duration value", roleName)
 
 }
+
+// AssertIAMRolePermissionsBoundary asserts that the permissions boundary of an IAM Role is set to the managed policy with the given ARN.
+func AssertIAMRolePermissionsBoundary(t *testing.T, ctx context.Context, client IAMClient, roleName string, policyARN string) {
+	output, err := getIAMRole(ctx, client, roleName)
+	require.NoError(t, err)
+
+	if output.Role.PermissionsBoundary == nil || output.Role.PermissionsBoundary.PermissionsBoundaryArn == nil {
+		assert.Fail(t, fmt.Sprintf("Role '%s' does not have a permissions boundary.", roleName))
+		return
+	}
+	assert.Equal(t, policyARN, *output.Role.PermissionsBoundary.PermissionsBoundaryArn, "Role '%s' has an incorrect permissions boundary.", roleName)
+}
+
+// AssertIAMRolePath asserts the path of an IAM Role, e.g. "/service-role/".
+func AssertIAMRolePath(t *testing.T, ctx context.Context, client IAMClient, roleName string, path string) {
+	output, err := getIAMRole(ctx, client, roleName)
+	require.NoError(t, err)
+
+	actualPath := ""
+	if output.Role.Path != nil {
+		actualPath = *output.Role.Path
+	}
+	assert.Equal(t, path, actualPath, "Role '%s' has an incorrect path.", roleName)
+}
+
+/*
+AssertIAMRoleTags asserts that an IAM Role has each of the given tags. A tag whose expected value is empty only needs to be present,
+with any value. Tags of the role that are not listed are ignored.
+
+# Examples
+
+Assert that a role is tagged with its owning team and any cost center.
+
+	AssertIAMRoleTags(t, ctx, client, "api", map[string]string{"team": "platform", "cost-center": ""})
+*/
+func AssertIAMRoleTags(t *testing.T, ctx context.Context, client IAMClient, roleName string, tags map[string]string) {
+	output, err := getIAMRole(ctx, client, roleName)
+	require.NoError(t, err)
+
+	actualTags := make(map[string]string, len(output.Role.Tags))
+	for _, tag := range output.Role.Tags {
+		if tag.Key == nil {
+			continue
+		}
+		value := ""
+		if tag.Value != nil {
+			value = *tag.Value
+		}
+		actualTags[*tag.Key] = value
+	}
+
+	// Sort the keys so that violations are reported consistently.
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var violations []string
+	for _, key := range keys {
+		actualValue, ok := actualTags[key]
+		switch {
+		case !ok:
+			violations = append(violations, fmt.Sprintf("tag '%s' is missing", key))
+		case tags[key] != "" && actualValue != tags[key]:
+			violations = append(violations, fmt.Sprintf("tag '%s' has value '%s', expected '%s'", key, actualValue, tags[key]))
+		}
+	}
+
+	assert.Empty(t, violations, "Role '%s' does not have the expected tags:\n%s", roleName, strings.Join(violations, "\n"))
+}
+
+/*
+AssertIAMRoleUsedWithinDays asserts that an IAM Role has been used within the given number of days. IAM only tracks the last use of
+a role within the past 400 days, so a role that has not been used for longer is reported as never used.
+
+# Examples
+
+Assert that a break glass role is exercised at least every quarter.
+
+	AssertIAMRoleUsedWithinDays(t, ctx, client, "break-glass", 90)
+*/
+func AssertIAMRoleUsedWithinDays(t *testing.T, ctx context.Context, client IAMClient, roleName string, days int) {
+	output, err := getIAMRole(ctx, client, roleName)
+	require.NoError(t, err)
+
+	if output.Role.RoleLastUsed == nil || output.Role.RoleLastUsed.LastUsedDate == nil {
+		assert.Fail(t, fmt.Sprintf("Role '%s' has not been used within the tracking period.", roleName))
+		return
+	}
+	lastUsed := *output.Role.RoleLastUsed.LastUsedDate
+	cutoff := time.Now().AddDate(0, 0, -days)
+	assert.False(t, lastUsed.Before(cutoff), "Role '%s' was last used on %s, more than %d days ago.", roleName, lastUsed.Format(time.RFC3339), days)
+}
+
+/*
+AssertIAMRoleAllowsAction asserts that the identity-based policies of an IAM Role allow an action on a resource. Every inline policy
+of the role and the default version of every managed policy attached to it are evaluated together, with the same rules as
+AssertIAMPolicyAllows. With WithIAMRolePermissionsBoundaryEvaluation, the permissions boundary of the role is also evaluated, and
+the action is only allowed if both the policies and the boundary allow it. A role without a permissions boundary is not restricted by
+one, as in AWS, so only its policies are evaluated; use AssertIAMRolePermissionsBoundary to assert that a role has a boundary.
+
+# Examples
+
+Assert that a service role can read secrets under its own path.
+
+	AssertIAMRoleAllowsAction(t, ctx, client, "api", "secretsmanager:GetSecretValue", "arn:aws:secretsmanager:us-east-1:123456789012:secret:api/database-AbCdEf")
+
+Assert that the permissions boundary of a role does not take away access that its policies grant.
+
+	AssertIAMRoleAllowsAction(t, ctx, client, "api", "s3:GetObject", "arn:aws:s3:::artifacts/builds/app.zip", WithIAMRolePermissionsBoundaryEvaluation())
+*/
+func AssertIAMRoleAllowsAction(t *testing.T, ctx context.Context, client IAMClient, roleName string, action string, resource string, optFns ...AssertIAMRoleAllowsActionOptsFunc) {
+	opts := &AssertIAMRoleAllowsActionOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	policyDocuments, err := getIAMRoleEffectivePolicyDocumentsE(ctx, client, roleName)
+	require.NoError(t, err)
+
+	request := IAMRequestContext{Action: action, Resource: resource}
+	decision, err := evaluateIAMPolicyDocuments(policyDocuments, request)
+	require.NoError(t, err)
+	if !assert.Equal(t, iamPolicyDecisionAllow, decision, "Action '%s' on resource '%s' is %s by the policies of role '%s'.", action, resource, decision, roleName) {
+		return
+	}
+	if !opts.EvaluatePermissionsBoundary {
+		return
+	}
+
+	boundaryDocument, ok, err := getIAMRolePermissionsBoundaryDocumentE(ctx, client, roleName)
+	require.NoError(t, err)
+	if !ok {
+		return
+	}
+	decision, err = evaluateIAMPolicyDocuments([]PolicyDocument{boundaryDocument}, request)
+	require.NoError(t, err)
+	assert.Equal(t, iamPolicyDecisionAllow, decision, "Action '%s' on resource '%s' is %s by the permissions boundary of role '%s'.", action, resource, decision, roleName)
+}
+
+// AssertIAMRoleAllowsActionOptions is a struct used for functional options for the AssertIAMRoleAllowsAction method.
+type AssertIAMRoleAllowsActionOptions struct {
+	// Whether to also evaluate the permissions boundary of the role. A role without a permissions boundary is not restricted by one.
+	EvaluatePermissionsBoundary bool
+}
+
+// AssertIAMRoleAllowsActionOptsFunc is a type used for functional options for the AssertIAMRoleAllowsAction method.
+type AssertIAMRoleAllowsActionOptsFunc func(*AssertIAMRoleAllowsActionOptions) error
+
+// WithIAMRolePermissionsBoundaryEvaluation evaluates the effective permissions of the role as the intersection of its policies and
+// its permissions boundary. If the role has no permissions boundary, its policies alone decide, as they do in AWS.
+func WithIAMRolePermissionsBoundaryEvaluation() AssertIAMRoleAllowsActionOptsFunc {
+	return func(opts *AssertIAMRoleAllowsActionOptions) error {
+		opts.EvaluatePermissionsBoundary = true
+		return nil
+	}
+}
+
+// getIAMRolePermissionsBoundaryDocumentE returns the default version of the permissions boundary of a Role, and false if the Role
+// does not have one.
+func getIAMRolePermissionsBoundaryDocumentE(ctx context.Context, client IAMClient, roleName string) (PolicyDocument, bool, error) {
+	output, err := getIAMRole(ctx, client, roleName)
+	if err != nil {
+		return PolicyDocument{}, false, err
+	}
+	if output.Role.PermissionsBoundary == nil || output.Role.PermissionsBoundary.PermissionsBoundaryArn == nil {
+		return PolicyDocument{}, false, nil
+	}
+	policyDocument, err := getIAMPolicyDefaultVersionE(ctx, *output.Role.PermissionsBoundary.PermissionsBoundaryArn, client)
+	return policyDocument, true, err
+}
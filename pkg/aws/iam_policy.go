@@ -0,0 +1,273 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	iamPolicyEffectAllow string = "Allow"
+	iamPolicyEffectDeny  string = "Deny"
+)
+
+// iamPolicyDecision is the result of evaluating a request against a set of policy documents.
+type iamPolicyDecision int
+
+const (
+	// iamPolicyDecisionImplicitDeny means that no statement allows the request.
+	iamPolicyDecisionImplicitDeny iamPolicyDecision = iota
+	// iamPolicyDecisionAllow means that at least one statement allows the request and none denies it.
+	iamPolicyDecisionAllow
+	// iamPolicyDecisionExplicitDeny means that at least one statement denies the request.
+	iamPolicyDecisionExplicitDeny
+)
+
+func (d iamPolicyDecision) String() string {
+	switch d {
+	case iamPolicyDecisionAllow:
+		return "allowed"
+	case iamPolicyDecisionExplicitDeny:
+		return "explicitly denied"
+	}
+	return "implicitly denied"
+}
+
+// IAMPrincipalType is the type of a principal in the Principal element of a policy.
+type IAMPrincipalType string
+
+const (
+	// IAMPrincipalTypeAWS is an AWS account, or an IAM role or user in an account.
+	IAMPrincipalTypeAWS IAMPrincipalType = "AWS"
+	// IAMPrincipalTypeService is an AWS service, e.g. "ec2.amazonaws.com".
+	IAMPrincipalTypeService IAMPrincipalType = "Service"
+	// IAMPrincipalTypeFederated is a web identity, OIDC or SAML provider.
+	IAMPrincipalTypeFederated IAMPrincipalType = "Federated"
+)
+
+const (
+	// iamPrincipalAnyone is the Principal element value that matches every principal.
+	iamPrincipalAnyone string = "*"
+	// iamPrincipalRootResource is the resource part of the ARN that denotes a whole account.
+	iamPrincipalRootResource string = "root"
+)
+
+// IAMPrincipal is a principal that a policy may grant access to.
+type IAMPrincipal struct {
+	// The type of the principal.
+	Type IAMPrincipalType
+	// The identifier of the principal: for AWS principals, either a 12 digit account ID or the ARN of a role or user; for services,
+	// the service principal name; for federated principals, the provider ARN or name.
+	ID string
+}
+
+/*
+AssertIAMPolicyAllows asserts that a set of IAM Policy Documents, evaluated together, allow an action on a resource. The evaluation
+follows AWS semantics: actions and resources may contain the "*" and "?" wildcards, actions are matched case-insensitively,
+NotAction and NotResource match everything except the values listed, and an explicit Deny in any document overrides every Allow.
+The request has no condition keys, so statements with conditions on keys that must be present do not apply; use
+AssertIAMPolicyAllowsRequest to evaluate conditions.
+
+# Examples
+
+Assert that the policies of a role allow it to read a specific object, which is granted by "s3:Get*" on "arn:aws:s3:::artifacts/*".
+
+	AssertIAMPolicyAllows(t, "arn:aws:s3:::artifacts/builds/app.zip", "s3:GetObject", policyDocuments)
+*/
+func AssertIAMPolicyAllows(t *testing.T, resource string, action string, policyDocuments []PolicyDocument) {
+	AssertIAMPolicyAllowsRequest(t, IAMRequestContext{Action: action, Resource: resource}, policyDocuments)
+}
+
+// AssertIAMPolicyDenies asserts that a set of IAM Policy Documents, evaluated together, do not allow an action on a resource, either
+// because a statement explicitly denies it or because no statement allows it. See AssertIAMPolicyAllows for the evaluation rules.
+func AssertIAMPolicyDenies(t *testing.T, resource string, action string, policyDocuments []PolicyDocument) {
+	AssertIAMPolicyDeniesRequest(t, IAMRequestContext{Action: action, Resource: resource}, policyDocuments)
+}
+
+/*
+AssertIAMPolicyAllowsRequest asserts that a set of IAM Policy Documents, evaluated together, allow a request, including the
+evaluation of any conditions against the condition keys of the request. See AssertIAMPolicyAllows for the other evaluation rules.
+
+# Examples
+
+Assert that reading an object is only allowed from inside the expected VPC.
+
+	request := IAMRequestContext{
+		Action:      "s3:GetObject",
+		Resource:    "arn:aws:s3:::artifacts/builds/app.zip",
+		ContextKeys: map[string][]string{"aws:SourceVpc": {"vpc-0123456789abcdef0"}},
+	}
+	AssertIAMPolicyAllowsRequest(t, request, policyDocuments)
+
+	request.ContextKeys = map[string][]string{"aws:SourceVpc": {"vpc-0fedcba9876543210"}}
+	AssertIAMPolicyDeniesRequest(t, request, policyDocuments)
+*/
+func AssertIAMPolicyAllowsRequest(t *testing.T, request IAMRequestContext, policyDocuments []PolicyDocument) {
+	decision, err := evaluateIAMPolicyDocuments(policyDocuments, request)
+	require.NoError(t, err)
+	assert.Equal(t, iamPolicyDecisionAllow, decision, "Action '%s' on resource '%s' is %s by the provided policy documents.", request.Action, request.Resource, decision)
+}
+
+// AssertIAMPolicyDeniesRequest asserts that a set of IAM Policy Documents, evaluated together, do not allow a request, including the
+// evaluation of any conditions against the condition keys of the request.
+func AssertIAMPolicyDeniesRequest(t *testing.T, request IAMRequestContext, policyDocuments []PolicyDocument) {
+	decision, err := evaluateIAMPolicyDocuments(policyDocuments, request)
+	require.NoError(t, err)
+	assert.NotEqual(t, iamPolicyDecisionAllow, decision, "Action '%s' on resource '%s' is allowed by the provided policy documents.", request.Action, request.Resource)
+}
+
+// evaluateIAMPolicyDocuments evaluates a request against every statement of a set of policy documents. An explicit Deny in any
+// statement takes precedence over every Allow. An error is returned if a statement uses a condition operator that is not supported.
+func evaluateIAMPolicyDocuments(policyDocuments []PolicyDocument, request IAMRequestContext) (iamPolicyDecision, error) {
+	decision := iamPolicyDecisionImplicitDeny
+	for _, policyDocument := range policyDocuments {
+		for _, statement := range policyDocument.Statement {
+			matched, err := iamPolicyStatementMatches(statement, request)
+			if err != nil {
+				return iamPolicyDecisionImplicitDeny, err
+			}
+			if !matched {
+				continue
+			}
+			switch statement.Effect {
+			case iamPolicyEffectDeny:
+				return iamPolicyDecisionExplicitDeny, nil
+			case iamPolicyEffectAllow:
+				decision = iamPolicyDecisionAllow
+			}
+		}
+	}
+	return decision, nil
+}
+
+// iamPolicyStatementMatches returns true if a statement applies to a request. Actions are matched case-insensitively, resources
+// case-sensitively. A statement without an action element does not apply to anything.
+func iamPolicyStatementMatches(statement StatementEntry, request IAMRequestContext) (bool, error) {
+	var actionMatched bool
+	switch {
+	case statement.Action != nil:
+		actionMatched = iamPolicyFieldMatches(statement.Action, request.Action, true)
+	case statement.NotAction != nil:
+		actionMatched = !iamPolicyFieldMatches(statement.NotAction, request.Action, true)
+	}
+	if !actionMatched {
+		return false, nil
+	}
+
+	// A statement without a resource element, as in a role trust policy, applies to the resource that the policy is attached to.
+	resourceMatched := true
+	switch {
+	case statement.Resource != nil:
+		resourceMatched = iamPolicyFieldMatches(statement.Resource, request.Resource, false)
+	case statement.NotResource != nil:
+		resourceMatched = !iamPolicyFieldMatches(statement.NotResource, request.Resource, false)
+	}
+	if !resourceMatched {
+		return false, nil
+	}
+
+	if request.Principal != nil {
+		principalMatched := false
+		switch {
+		case statement.Principal != nil:
+			principalMatched = iamPolicyPrincipalMatches(statement.Principal, *request.Principal)
+		case statement.NotPrincipal != nil:
+			principalMatched = !iamPolicyPrincipalMatches(statement.NotPrincipal, *request.Principal)
+		}
+		if !principalMatched {
+			return false, nil
+		}
+	}
+
+	return iamConditionBlockMatches(statement.Condition, request)
+}
+
+// iamPolicyFieldMatches returns true if a value matches any of the patterns in an Action, NotAction, Resource or NotResource field.
+func iamPolicyFieldMatches(field interface{}, value string, ignoreCase bool) bool {
+	for _, pattern := range parseIAMPolicyField(field) {
+		if ignoreCase {
+			pattern = strings.ToLower(pattern)
+			value = strings.ToLower(value)
+		}
+		if iamPolicyPatternMatches(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// iamPolicyPatternMatches returns true if a value matches a policy pattern, in which "*" matches any sequence of characters and "?"
+// matches any single character.
+func iamPolicyPatternMatches(pattern string, value string) bool {
+	patternRunes, valueRunes := []rune(pattern), []rune(value)
+	p, v := 0, 0
+	// The positions after the last "*" seen and the value character it was matched up to, used to backtrack on a mismatch.
+	starP, starV := -1, 0
+	for v < len(valueRunes) {
+		switch {
+		case p < len(patternRunes) && patternRunes[p] == '*':
+			starP, starV = p+1, v
+			p++
+		case p < len(patternRunes) && (patternRunes[p] == '?' || patternRunes[p] == valueRunes[v]):
+			p++
+			v++
+		case starP != -1:
+			// Let the last "*" match one more character and retry the rest of the pattern.
+			starV++
+			p, v = starP, starV
+		default:
+			return false
+		}
+	}
+	for p < len(patternRunes) && patternRunes[p] == '*' {
+		p++
+	}
+	return p == len(patternRunes)
+}
+
+// iamPolicyPrincipalMatches returns true if a principal is one of those listed in a Principal or NotPrincipal element. The element
+// may be "*", which matches every principal, or a map of principal types to one or more identifiers.
+func iamPolicyPrincipalMatches(field interface{}, principal IAMPrincipal) bool {
+	if value, ok := field.(string); ok {
+		return value == iamPrincipalAnyone
+	}
+	principals, ok := field.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for principalType, ids := range principals {
+		if IAMPrincipalType(principalType) != principal.Type {
+			continue
+		}
+		for _, id := range parseIAMPolicyField(ids) {
+			if id == iamPrincipalAnyone || id == principal.ID {
+				return true
+			}
+			if principal.Type == IAMPrincipalTypeAWS && iamAWSPrincipalInAccount(id, principal.ID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// iamAWSPrincipalInAccount returns true if a policy principal denotes a whole account, either by ID or by root user ARN, and a role
+// or user ARN belongs to that account. A principal given as an account ID only matches the account itself.
+func iamAWSPrincipalInAccount(policyID string, principalID string) bool {
+	accountID := policyID
+	if parts := strings.Split(policyID, ":"); len(parts) == 6 && parts[0] == "arn" {
+		if parts[5] != iamPrincipalRootResource {
+			return false
+		}
+		accountID = parts[4]
+	}
+	parts := strings.Split(principalID, ":")
+	if len(parts) == 6 && parts[0] == "arn" {
+		return parts[4] == accountID
+	}
+	return principalID == accountID
+}
@@ -0,0 +1,268 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	iamConditionForAnyValuePrefix  string = "ForAnyValue:"
+	iamConditionForAllValuesPrefix string = "ForAllValues:"
+	iamConditionIfExistsSuffix     string = "IfExists"
+	iamConditionOperatorNull       string = "Null"
+)
+
+// iamConditionComparators maps each positive condition operator to a function that compares a single value from the request
+// context with a single value from the policy.
+var iamConditionComparators = map[string]func(requestValue string, policyValue string) bool{
+	"StringEquals":             func(r, p string) bool { return r == p },
+	"StringEqualsIgnoreCase":   strings.EqualFold,
+	"StringLike":               func(r, p string) bool { return iamPolicyPatternMatches(p, r) },
+	"NumericEquals":            iamConditionNumericComparator(func(r, p float64) bool { return r == p }),
+	"NumericLessThan":          iamConditionNumericComparator(func(r, p float64) bool { return r < p }),
+	"NumericLessThanEquals":    iamConditionNumericComparator(func(r, p float64) bool { return r <= p }),
+	"NumericGreaterThan":       iamConditionNumericComparator(func(r, p float64) bool { return r > p }),
+	"NumericGreaterThanEquals": iamConditionNumericComparator(func(r, p float64) bool { return r >= p }),
+	"DateEquals":               iamConditionDateComparator(func(r, p time.Time) bool { return r.Equal(p) }),
+	"DateLessThan":             iamConditionDateComparator(func(r, p time.Time) bool { return r.Before(p) }),
+	"DateLessThanEquals":       iamConditionDateComparator(func(r, p time.Time) bool { return !r.After(p) }),
+	"DateGreaterThan":          iamConditionDateComparator(func(r, p time.Time) bool { return r.After(p) }),
+	"DateGreaterThanEquals":    iamConditionDateComparator(func(r, p time.Time) bool { return !r.Before(p) }),
+	"Bool":                     strings.EqualFold,
+	"IpAddress":                iamConditionIPAddressMatches,
+	// ArnEquals and ArnLike behave identically; both accept wildcards.
+	"ArnEquals": func(r, p string) bool { return iamPolicyPatternMatches(p, r) },
+	"ArnLike":   func(r, p string) bool { return iamPolicyPatternMatches(p, r) },
+}
+
+// iamConditionNegatedOperators maps each negated condition operator to the positive operator that it negates.
+var iamConditionNegatedOperators = map[string]string{
+	"StringNotEquals":           "StringEquals",
+	"StringNotEqualsIgnoreCase": "StringEqualsIgnoreCase",
+	"StringNotLike":             "StringLike",
+	"NumericNotEquals":          "NumericEquals",
+	"DateNotEquals":             "DateEquals",
+	"NotIpAddress":              "IpAddress",
+	"ArnNotEquals":              "ArnEquals",
+	"ArnNotLike":                "ArnLike",
+}
+
+/*
+IAMRequestContext describes a request to evaluate against IAM Policy Documents, including the values of any condition keys that
+the policies may test. Condition keys that are not set are treated as absent from the request, as AWS does.
+
+# Examples
+
+A request to read an object from inside a VPC, by a principal in an AWS Organization.
+
+	request := IAMRequestContext{
+		Action:   "s3:GetObject",
+		Resource: "arn:aws:s3:::artifacts/builds/app.zip",
+		ContextKeys: map[string][]string{
+			"aws:SourceVpc":      {"vpc-0123456789abcdef0"},
+			"aws:PrincipalOrgID": {"o-a1b2c3d4e5"},
+		},
+	}
+*/
+type IAMRequestContext struct {
+	// The action being requested, e.g. "s3:GetObject".
+	Action string
+	// The ARN of the resource that the action is requested on.
+	Resource string
+	// The principal making the request, which is matched against the Principal and NotPrincipal elements of resource-based policies
+	// such as role trust policies. If it is nil, those elements are ignored, as they are for identity-based policies.
+	Principal *IAMPrincipal
+	// The values of condition keys in the request, keyed by condition key. Keys are matched case-insensitively. Multi-valued keys,
+	// such as "aws:TagKeys", may have several values.
+	ContextKeys map[string][]string
+}
+
+// iamConditionBlockMatches returns true if a request satisfies every condition of a statement. An error is returned if the block
+// uses an operator that is not supported.
+func iamConditionBlockMatches(condition map[string]map[string]interface{}, request IAMRequestContext) (bool, error) {
+	// Sort the operators so that errors are reported consistently.
+	operators := make([]string, 0, len(condition))
+	for operator := range condition {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+
+	for _, operator := range operators {
+		for key, value := range condition[operator] {
+			requestValues, present := getIAMRequestContextValues(request, key)
+			matched, err := iamConditionMatches(operator, requestValues, present, parseIAMConditionValues(value))
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// iamConditionMatches evaluates a single condition operator against the values of one condition key in the request.
+func iamConditionMatches(operator string, requestValues []string, present bool, policyValues []string) (bool, error) {
+	baseOperator := operator
+	forAnyValue := strings.HasPrefix(baseOperator, iamConditionForAnyValuePrefix)
+	forAllValues := strings.HasPrefix(baseOperator, iamConditionForAllValuesPrefix)
+	baseOperator = strings.TrimPrefix(strings.TrimPrefix(baseOperator, iamConditionForAnyValuePrefix), iamConditionForAllValuesPrefix)
+
+	if baseOperator == iamConditionOperatorNull {
+		if len(policyValues) == 0 {
+			return false, fmt.Errorf("condition operator '%s' has no value", operator)
+		}
+		// A Null condition of "true" requires the key to be absent, and of "false" requires it to be present.
+		return strings.EqualFold(policyValues[0], "true") != present, nil
+	}
+
+	ifExists := strings.HasSuffix(baseOperator, iamConditionIfExistsSuffix)
+	baseOperator = strings.TrimSuffix(baseOperator, iamConditionIfExistsSuffix)
+	negated := false
+	if positiveOperator, ok := iamConditionNegatedOperators[baseOperator]; ok {
+		baseOperator = positiveOperator
+		negated = true
+	}
+	comparator, ok := iamConditionComparators[baseOperator]
+	if !ok {
+		return false, fmt.Errorf("condition operator '%s' is not supported", operator)
+	}
+
+	// valueMatches applies the operator to a single value from the request. A negated operator is satisfied if the value matches
+	// none of the values in the policy.
+	valueMatches := func(requestValue string) bool {
+		for _, policyValue := range policyValues {
+			if comparator(requestValue, policyValue) {
+				return !negated
+			}
+		}
+		return negated
+	}
+
+	switch {
+	case forAllValues:
+		for _, requestValue := range requestValues {
+			if !valueMatches(requestValue) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case forAnyValue:
+		for _, requestValue := range requestValues {
+			if valueMatches(requestValue) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case !present:
+		return ifExists || negated, nil
+	case negated:
+		for _, requestValue := range requestValues {
+			if !valueMatches(requestValue) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	for _, requestValue := range requestValues {
+		if valueMatches(requestValue) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getIAMRequestContextValues returns the values of a condition key in a request, matching the key case-insensitively, and whether
+// the key is present at all.
+func getIAMRequestContextValues(request IAMRequestContext, key string) ([]string, bool) {
+	for contextKey, values := range request.ContextKeys {
+		if strings.EqualFold(contextKey, key) {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+// parseIAMConditionValues converts the value of a condition key in a policy, which may be a single value or an array of strings,
+// numbers or booleans, to a list of strings.
+func parseIAMConditionValues(value interface{}) (values []string) {
+	switch typedValue := value.(type) {
+	case []interface{}:
+		for _, item := range typedValue {
+			values = append(values, parseIAMConditionValues(item)...)
+		}
+	case string:
+		values = append(values, typedValue)
+	case bool:
+		values = append(values, strconv.FormatBool(typedValue))
+	case float64:
+		values = append(values, strconv.FormatFloat(typedValue, 'f', -1, 64))
+	case nil:
+	default:
+		values = append(values, fmt.Sprint(typedValue))
+	}
+	return
+}
+
+// iamConditionNumericComparator returns a comparator that parses both values as numbers. Values that are not numbers never match.
+func iamConditionNumericComparator(compare func(requestValue float64, policyValue float64) bool) func(string, string) bool {
+	return func(requestValue string, policyValue string) bool {
+		r, err := strconv.ParseFloat(requestValue, 64)
+		if err != nil {
+			return false
+		}
+		p, err := strconv.ParseFloat(policyValue, 64)
+		if err != nil {
+			return false
+		}
+		return compare(r, p)
+	}
+}
+
+// iamConditionDateComparator returns a comparator that parses both values as dates, either in RFC 3339 format or as seconds since
+// the Unix epoch. Values that are not dates never match.
+func iamConditionDateComparator(compare func(requestValue time.Time, policyValue time.Time) bool) func(string, string) bool {
+	return func(requestValue string, policyValue string) bool {
+		r, ok := parseIAMConditionDate(requestValue)
+		if !ok {
+			return false
+		}
+		p, ok := parseIAMConditionDate(policyValue)
+		if !ok {
+			return false
+		}
+		return compare(r, p)
+	}
+}
+
+// parseIAMConditionDate parses a date in RFC 3339 format or as seconds since the Unix epoch.
+func parseIAMConditionDate(value string) (time.Time, bool) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, true
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	return time.Time{}, false
+}
+
+// iamConditionIPAddressMatches returns true if an IP address from the request is within a CIDR block, or equal to an IP address,
+// from the policy.
+func iamConditionIPAddressMatches(requestValue string, policyValue string) bool {
+	ip := net.ParseIP(requestValue)
+	if ip == nil {
+		return false
+	}
+	if _, network, err := net.ParseCIDR(policyValue); err == nil {
+		return network.Contains(ip)
+	}
+	policyIP := net.ParseIP(policyValue)
+	return policyIP != nil && policyIP.Equal(ip)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2/json"
+)
+
+const (
+	testIAMConditionPolicy string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Sid": "AllowFromVPC",
+				"Effect": "Allow",
+				"Principal": {"AWS": "arn:aws:iam::123456789012:root"},
+				"Action": "s3:GetObject",
+				"Resource": "arn:aws:s3:::artifacts/*",
+				"Condition": {
+					"StringEquals": {"aws:SourceVpc": "vpc-123456"},
+					"Bool": {"aws:SecureTransport": true}
+				}
+			},
+			{
+				"Sid": "DenyOutsideOrg",
+				"Effect": "Deny",
+				"NotPrincipal": {"AWS": "arn:aws:iam::123456789012:role/break-glass"},
+				"Action": "s3:*",
+				"Resource": "*",
+				"Condition": {
+					"StringNotEqualsIfExists": {"aws:PrincipalOrgID": "o-a1b2c3d4e5"}
+				}
+			}
+		]
+	}`
+)
+
+func newTestIAMConditionPolicyDocuments(t *testing.T) []PolicyDocument {
+	var policyDocument PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(testIAMConditionPolicy), &policyDocument))
+	return []PolicyDocument{policyDocument}
+}
+
+func newTestIAMConditionRequest() IAMRequestContext {
+	return IAMRequestContext{
+		Action:   "s3:GetObject",
+		Resource: "arn:aws:s3:::artifacts/builds/app.zip",
+		ContextKeys: map[string][]string{
+			"aws:sourcevpc":       {"vpc-123456"},
+			"aws:SecureTransport": {"true"},
+			"aws:PrincipalOrgID":  {"o-a1b2c3d4e5"},
+		},
+	}
+}
+
+func TestUnmarshalPolicyDocument_KeepsConditionAndPrincipal(t *testing.T) {
+	// Execute
+	policyDocuments := newTestIAMConditionPolicyDocuments(t)
+
+	// Assert
+	statement := policyDocuments[0].Statement[0]
+	assert.Equal(t, "AllowFromVPC", statement.Sid)
+	assert.Equal(t, map[string]interface{}{"AWS": "arn:aws:iam::123456789012:root"}, statement.Principal)
+	assert.Equal(t, "vpc-123456", statement.Condition["StringEquals"]["aws:SourceVpc"])
+	assert.NotNil(t, policyDocuments[0].Statement[1].NotPrincipal)
+}
+
+func TestAssertIAMPolicyAllowsRequest_ConditionsMet(t *testing.T) {
+	// Setup
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMPolicyAllowsRequest(fakeTest, newTestIAMConditionRequest(), newTestIAMConditionPolicyDocuments(t))
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMPolicyDeniesRequest_WrongVPC(t *testing.T) {
+	// Setup
+	request := newTestIAMConditionRequest()
+	request.ContextKeys["aws:sourcevpc"] = []string{"vpc-654321"}
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMPolicyDeniesRequest(fakeTest, request, newTestIAMConditionPolicyDocuments(t))
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestEvaluateIAMPolicyDocuments_ConditionalDeny(t *testing.T) {
+	// Setup
+	request := newTestIAMConditionRequest()
+	request.ContextKeys["aws:PrincipalOrgID"] = []string{"o-zzzzzzzzzz"}
+
+	// Execute
+	decision, err := evaluateIAMPolicyDocuments(newTestIAMConditionPolicyDocuments(t), request)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, iamPolicyDecisionExplicitDeny, decision)
+}
+
+func TestEvaluateIAMPolicyDocuments_UnsupportedOperator(t *testing.T) {
+	// Setup
+	policyDocuments := []PolicyDocument{
+		{
+			Statement: []StatementEntry{
+				{
+					Effect:    "Allow",
+					Action:    "*",
+					Resource:  "*",
+					Condition: map[string]map[string]interface{}{"BinaryEquals": {"aws:key": "dGVzdA=="}},
+				},
+			},
+		},
+	}
+
+	// Execute
+	_, err := evaluateIAMPolicyDocuments(policyDocuments, newTestIAMConditionRequest())
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestIAMConditionMatches_Operators(t *testing.T) {
+	matches := func(operator string, requestValues []string, present bool, policyValues ...string) bool {
+		matched, err := iamConditionMatches(operator, requestValues, present, policyValues)
+		require.NoError(t, err)
+		return matched
+	}
+
+	assert.True(t, matches("StringLike", []string{"arn:aws:iam::123456789012:role/app-api"}, true, "arn:aws:iam::*:role/app-*"))
+	assert.True(t, matches("StringEqualsIgnoreCase", []string{"PROD"}, true, "prod"))
+	assert.True(t, matches("ArnLike", []string{"arn:aws:sns:us-east-1:123456789012:alerts"}, true, "arn:aws:sns:*:123456789012:*"))
+	assert.True(t, matches("IpAddress", []string{"10.0.1.15"}, true, "10.0.0.0/16"))
+	assert.False(t, matches("IpAddress", []string{"192.168.0.1"}, true, "10.0.0.0/16"))
+	assert.True(t, matches("NotIpAddress", []string{"192.168.0.1"}, true, "10.0.0.0/16"))
+	assert.True(t, matches("NumericLessThan", []string{"3600"}, true, "7200"))
+	assert.False(t, matches("NumericLessThan", []string{"not-a-number"}, true, "7200"))
+	assert.True(t, matches("DateGreaterThan", []string{"2024-03-01T00:00:00Z"}, true, "2024-01-01T00:00:00Z"))
+	assert.True(t, matches("Bool", []string{"TRUE"}, true, "true"))
+	assert.False(t, matches("StringEquals", nil, false, "vpc-123456"))
+	assert.True(t, matches("StringEqualsIfExists", nil, false, "vpc-123456"))
+	assert.True(t, matches("StringNotEquals", nil, false, "vpc-123456"))
+	assert.True(t, matches("Null", nil, false, "true"))
+	assert.False(t, matches("Null", []string{"vpc-123456"}, true, "true"))
+}
+
+func TestIAMConditionMatches_SetOperators(t *testing.T) {
+	matches := func(operator string, requestValues []string, present bool, policyValues ...string) bool {
+		matched, err := iamConditionMatches(operator, requestValues, present, policyValues)
+		require.NoError(t, err)
+		return matched
+	}
+
+	assert.True(t, matches("ForAllValues:StringEquals", []string{"team", "env"}, true, "team", "env", "owner"))
+	assert.False(t, matches("ForAllValues:StringEquals", []string{"team", "cost"}, true, "team", "env", "owner"))
+	assert.True(t, matches("ForAllValues:StringEquals", nil, false, "team"))
+	assert.True(t, matches("ForAnyValue:StringEquals", []string{"team", "cost"}, true, "cost"))
+	assert.False(t, matches("ForAnyValue:StringEquals", nil, false, "cost"))
+	assert.False(t, matches("ForAnyValue:StringNotEquals", []string{"team"}, true, "team"))
+}
@@ -0,0 +1,112 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIAMPolicyDocuments() []PolicyDocument {
+	return []PolicyDocument{
+		{
+			Version: "2012-10-17",
+			Statement: []StatementEntry{
+				{
+					Effect:   "Allow",
+					Action:   "s3:*",
+					Resource: "arn:aws:s3:::artifacts/*",
+				},
+				{
+					Effect:      "Allow",
+					NotAction:   []interface{}{"iam:*", "organizations:*"},
+					NotResource: "arn:aws:s3:::*",
+				},
+			},
+		},
+		{
+			Version: "2012-10-17",
+			Statement: []StatementEntry{
+				{
+					Effect:   "Deny",
+					Action:   "s3:DeleteObject",
+					Resource: "arn:aws:s3:::artifacts/releases/*",
+				},
+			},
+		},
+	}
+}
+
+func TestEvaluateIAMPolicyDocuments_WildcardAllow(t *testing.T) {
+	// Execute
+	decision, err := evaluateIAMPolicyDocuments(newTestIAMPolicyDocuments(), IAMRequestContext{Action: "S3:GetObject", Resource: "arn:aws:s3:::artifacts/builds/app.zip"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, iamPolicyDecisionAllow, decision)
+}
+
+func TestEvaluateIAMPolicyDocuments_ExplicitDenyWins(t *testing.T) {
+	// Execute
+	decision, err := evaluateIAMPolicyDocuments(newTestIAMPolicyDocuments(), IAMRequestContext{Action: "s3:DeleteObject", Resource: "arn:aws:s3:::artifacts/releases/1.0.0.zip"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, iamPolicyDecisionExplicitDeny, decision)
+}
+
+func TestEvaluateIAMPolicyDocuments_NotActionNotResource(t *testing.T) {
+	// Setup
+	policyDocuments := newTestIAMPolicyDocuments()
+
+	// Execute
+	ec2Decision, ec2Err := evaluateIAMPolicyDocuments(policyDocuments, IAMRequestContext{Action: "ec2:DescribeInstances", Resource: "*"})
+	iamDecision, iamErr := evaluateIAMPolicyDocuments(policyDocuments, IAMRequestContext{Action: "iam:CreateUser", Resource: "arn:aws:iam::123456789012:user/admin"})
+	otherBucketDecision, otherBucketErr := evaluateIAMPolicyDocuments(policyDocuments, IAMRequestContext{Action: "s3:GetObject", Resource: "arn:aws:s3:::secrets/key"})
+
+	// Assert
+	require.NoError(t, ec2Err)
+	require.NoError(t, iamErr)
+	require.NoError(t, otherBucketErr)
+	assert.Equal(t, iamPolicyDecisionAllow, ec2Decision)
+	assert.Equal(t, iamPolicyDecisionImplicitDeny, iamDecision)
+	assert.Equal(t, iamPolicyDecisionImplicitDeny, otherBucketDecision)
+}
+
+func TestIAMPolicyPatternMatches(t *testing.T) {
+	assert.True(t, iamPolicyPatternMatches("arn:aws:s3:::bucket/*", "arn:aws:s3:::bucket/a/b.txt"))
+	assert.True(t, iamPolicyPatternMatches("arn:aws:s3:::bucket-?", "arn:aws:s3:::bucket-1"))
+	assert.False(t, iamPolicyPatternMatches("arn:aws:s3:::bucket-?", "arn:aws:s3:::bucket-10"))
+	assert.False(t, iamPolicyPatternMatches("arn:aws:s3:::bucket.name", "arn:aws:s3:::bucketxname"))
+	assert.False(t, iamPolicyPatternMatches("arn:aws:s3:::Bucket/*", "arn:aws:s3:::bucket/key"))
+	assert.True(t, iamPolicyPatternMatches("*", ""))
+	assert.True(t, iamPolicyPatternMatches("arn:aws:iam::*:role/*-admin", "arn:aws:iam::123456789012:role/team-a-admin"))
+	assert.False(t, iamPolicyPatternMatches("arn:aws:iam::*:role/*-admin", "arn:aws:iam::123456789012:role/team-a-admin-old"))
+	assert.True(t, iamPolicyPatternMatches("a*b?c*", "axxbyczz"))
+	assert.False(t, iamPolicyPatternMatches("a?", "a"))
+}
+
+func TestAssertIAMPolicyAllows_ImplicitDeny(t *testing.T) {
+	// Setup
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMPolicyAllows(fakeTest, "arn:aws:iam::123456789012:role/admin", "iam:PassRole", newTestIAMPolicyDocuments())
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMPolicyDenies_ExplicitDeny(t *testing.T) {
+	// Setup
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMPolicyDenies(fakeTest, "arn:aws:s3:::artifacts/releases/1.0.0.zip", "s3:DeleteObject", newTestIAMPolicyDocuments())
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
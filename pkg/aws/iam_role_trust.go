@@ -0,0 +1,178 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	iamActionAssumeRole                string = "sts:AssumeRole"
+	iamActionAssumeRoleWithWebIdentity string = "sts:AssumeRoleWithWebIdentity"
+	iamActionAssumeRoleWithSAML        string = "sts:AssumeRoleWithSAML"
+	iamSAMLProviderResourcePrefix      string = "saml-provider/"
+)
+
+// AssertIAMRoleTrustOptions is a struct used for functional options for the IAM role trust assertion methods.
+type AssertIAMRoleTrustOptions struct {
+	// The action used to assume the role. Defaults to "sts:AssumeRoleWithSAML" for SAML providers,
+	// "sts:AssumeRoleWithWebIdentity" for other federated principals and "sts:AssumeRole" for everything else.
+	Action string
+	// The values of condition keys in the request to assume the role, e.g. "sts:ExternalId" or an OIDC "sub" claim.
+	ContextKeys map[string][]string
+	// Condition keys without which the role must not be assumable. Only used by AssertIAMRoleTrustsPrincipal.
+	RequiredConditionKeys []string
+}
+
+// AssertIAMRoleTrustOptsFunc is a type used for functional options for the IAM role trust assertion methods.
+type AssertIAMRoleTrustOptsFunc func(*AssertIAMRoleTrustOptions) error
+
+// WithIAMRoleTrustAction sets the action used to assume the role.
+func WithIAMRoleTrustAction(action string) AssertIAMRoleTrustOptsFunc {
+	return func(opts *AssertIAMRoleTrustOptions) error {
+		opts.Action = action
+		return nil
+	}
+}
+
+// WithIAMRoleTrustContextKeys sets the values of condition keys in the request to assume the role.
+func WithIAMRoleTrustContextKeys(contextKeys map[string][]string) AssertIAMRoleTrustOptsFunc {
+	return func(opts *AssertIAMRoleTrustOptions) error {
+		opts.ContextKeys = contextKeys
+		return nil
+	}
+}
+
+// WithIAMRoleTrustRequiredConditionKeys asserts that the role cannot be assumed if any of the given condition keys is missing from
+// the request, e.g. that the trust policy requires "sts:ExternalId". Each key must also be set with WithIAMRoleTrustContextKeys.
+func WithIAMRoleTrustRequiredConditionKeys(keys ...string) AssertIAMRoleTrustOptsFunc {
+	return func(opts *AssertIAMRoleTrustOptions) error {
+		opts.RequiredConditionKeys = append(opts.RequiredConditionKeys, keys...)
+		return nil
+	}
+}
+
+/*
+AssertIAMRoleTrustsPrincipal asserts that the trust policy of an IAM Role allows a principal to assume it. A principal given as an
+ARN is also trusted if the policy trusts its whole account.
+
+# Examples
+
+Assert that EC2 instances can assume a role.
+
+	AssertIAMRoleTrustsPrincipal(t, ctx, client, "api", IAMPrincipal{Type: IAMPrincipalTypeService, ID: "ec2.amazonaws.com"})
+
+Assert that a third party can assume a role only when it passes the agreed external ID.
+
+	AssertIAMRoleTrustsPrincipal(t, ctx, client, "vendor-access", IAMPrincipal{Type: IAMPrincipalTypeAWS, ID: "210987654321"},
+		WithIAMRoleTrustContextKeys(map[string][]string{"sts:ExternalId": {"a1b2c3"}}),
+		WithIAMRoleTrustRequiredConditionKeys("sts:ExternalId"),
+	)
+
+Assert that only the main branch of a repository can assume a role through GitHub's OIDC provider.
+
+	AssertIAMRoleTrustsPrincipal(t, ctx, client, "deploy",
+		IAMPrincipal{Type: IAMPrincipalTypeFederated, ID: "arn:aws:iam::123456789012:oidc-provider/token.actions.githubusercontent.com"},
+		WithIAMRoleTrustContextKeys(map[string][]string{
+			"token.actions.githubusercontent.com:sub": {"repo:example/app:ref:refs/heads/main"},
+			"token.actions.githubusercontent.com:aud": {"sts.amazonaws.com"},
+		}),
+		WithIAMRoleTrustRequiredConditionKeys("token.actions.githubusercontent.com:sub"),
+	)
+*/
+func AssertIAMRoleTrustsPrincipal(t *testing.T, ctx context.Context, client IAMClient, roleName string, principal IAMPrincipal, optFns ...AssertIAMRoleTrustOptsFunc) {
+	opts := getIAMRoleTrustOptions(t, optFns)
+	trustPolicy, request := getIAMRoleTrustAssertionInputs(t, ctx, client, roleName, principal, opts)
+
+	decision, err := evaluateIAMPolicyDocuments([]PolicyDocument{trustPolicy}, request)
+	require.NoError(t, err)
+	if !assert.Equal(t, iamPolicyDecisionAllow, decision, "Role '%s' does not trust %s principal '%s' to perform '%s'.", roleName, principal.Type, principal.ID, request.Action) {
+		return
+	}
+
+	var violations []string
+	for _, key := range opts.RequiredConditionKeys {
+		requestWithoutKey := request
+		requestWithoutKey.ContextKeys = make(map[string][]string)
+		for contextKey, values := range request.ContextKeys {
+			if !strings.EqualFold(contextKey, key) {
+				requestWithoutKey.ContextKeys[contextKey] = values
+			}
+		}
+		decision, err := evaluateIAMPolicyDocuments([]PolicyDocument{trustPolicy}, requestWithoutKey)
+		require.NoError(t, err)
+		if decision == iamPolicyDecisionAllow {
+			violations = append(violations, fmt.Sprintf("the role can be assumed without condition key '%s'", key))
+		}
+	}
+
+	assert.Empty(t, violations, "Trust policy of role '%s' does not require the expected conditions:\n%s", roleName, strings.Join(violations, "\n"))
+}
+
+/*
+AssertIAMRoleDoesNotTrustPrincipal asserts that the trust policy of an IAM Role does not allow a principal to assume it.
+
+# Examples
+
+Assert that a role cannot be assumed by an arbitrary account, e.g. through a "*" principal.
+
+	AssertIAMRoleDoesNotTrustPrincipal(t, ctx, client, "api", IAMPrincipal{Type: IAMPrincipalTypeAWS, ID: "999999999999"})
+*/
+func AssertIAMRoleDoesNotTrustPrincipal(t *testing.T, ctx context.Context, client IAMClient, roleName string, principal IAMPrincipal, optFns ...AssertIAMRoleTrustOptsFunc) {
+	opts := getIAMRoleTrustOptions(t, optFns)
+	trustPolicy, request := getIAMRoleTrustAssertionInputs(t, ctx, client, roleName, principal, opts)
+
+	decision, err := evaluateIAMPolicyDocuments([]PolicyDocument{trustPolicy}, request)
+	require.NoError(t, err)
+	assert.NotEqual(t, iamPolicyDecisionAllow, decision, "Role '%s' trusts %s principal '%s' to perform '%s'.", roleName, principal.Type, principal.ID, request.Action)
+}
+
+// getIAMRoleTrustAssertionInputs returns the parsed trust policy of a role and the request to evaluate against it.
+func getIAMRoleTrustAssertionInputs(t *testing.T, ctx context.Context, client IAMClient, roleName string, principal IAMPrincipal, opts *AssertIAMRoleTrustOptions) (PolicyDocument, IAMRequestContext) {
+	output, err := getIAMRole(ctx, client, roleName)
+	require.NoError(t, err)
+	require.NotNil(t, output.Role.AssumeRolePolicyDocument, "Role '%s' does not have a trust policy.", roleName)
+	trustPolicy, err := unMarshallPolicyDocument(*output.Role.AssumeRolePolicyDocument)
+	require.NoError(t, err)
+
+	action := opts.Action
+	if action == "" {
+		action = getIAMRoleTrustDefaultAction(principal)
+	}
+	request := IAMRequestContext{
+		Action:      action,
+		Principal:   &principal,
+		ContextKeys: opts.ContextKeys,
+	}
+	if output.Role.Arn != nil {
+		request.Resource = *output.Role.Arn
+	}
+	return *trustPolicy, request
+}
+
+// getIAMRoleTrustOptions applies the functional options for the IAM role trust assertion methods.
+func getIAMRoleTrustOptions(t *testing.T, optFns []AssertIAMRoleTrustOptsFunc) *AssertIAMRoleTrustOptions {
+	opts := &AssertIAMRoleTrustOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+	return opts
+}
+
+// getIAMRoleTrustDefaultAction returns the action that a principal uses to assume a role.
+func getIAMRoleTrustDefaultAction(principal IAMPrincipal) string {
+	if principal.Type != IAMPrincipalTypeFederated {
+		return iamActionAssumeRole
+	}
+	if strings.Contains(principal.ID, iamSAMLProviderResourcePrefix) {
+		return iamActionAssumeRoleWithSAML
+	}
+	return iamActionAssumeRoleWithWebIdentity
+}
@@ -0,0 +1,236 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testIAMRoleTrustPolicy string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": {"Service": ["ec2.amazonaws.com", "lambda.amazonaws.com"]},
+				"Action": "sts:AssumeRole"
+			},
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": "arn:aws:iam::210987654321:root"},
+				"Action": "sts:AssumeRole",
+				"Condition": {"StringEquals": {"sts:ExternalId": "a1b2c3"}}
+			},
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": "345678901234"},
+				"Action": "sts:AssumeRole"
+			},
+			{
+				"Effect": "Allow",
+				"Principal": {"Federated": "arn:aws:iam::123456789012:oidc-provider/token.actions.githubusercontent.com"},
+				"Action": "sts:AssumeRoleWithWebIdentity",
+				"Condition": {
+					"StringEquals": {"token.actions.githubusercontent.com:aud": "sts.amazonaws.com"},
+					"StringLike": {"token.actions.githubusercontent.com:sub": "repo:example/app:ref:refs/heads/*"}
+				}
+			}
+		]
+	}`
+	testIAMRoleTrustOIDCProvider string = "arn:aws:iam::123456789012:oidc-provider/token.actions.githubusercontent.com"
+)
+
+func TestAssertIAMRoleTrustsPrincipal_Service(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{
+			Role: &types.Role{
+				RoleName:                 &roleName,
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/testIam"),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(testIAMRoleTrustPolicy)),
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleTrustsPrincipal(fakeTest, ctx, client, roleName, IAMPrincipal{Type: IAMPrincipalTypeService, ID: "ec2.amazonaws.com"})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleTrustsPrincipal_ExternalIDRequired(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{
+			Role: &types.Role{
+				RoleName:                 &roleName,
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/testIam"),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(testIAMRoleTrustPolicy)),
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleTrustsPrincipal(fakeTest, ctx, client, roleName,
+		IAMPrincipal{Type: IAMPrincipalTypeAWS, ID: "arn:aws:iam::210987654321:role/vendor"},
+		WithIAMRoleTrustContextKeys(map[string][]string{"sts:ExternalId": {"a1b2c3"}}),
+		WithIAMRoleTrustRequiredConditionKeys("sts:ExternalId"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleTrustsPrincipal_ExternalIDNotRequired(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{
+			Role: &types.Role{
+				RoleName:                 &roleName,
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/testIam"),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(testIAMRoleTrustPolicy)),
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleTrustsPrincipal(fakeTest, ctx, client, roleName,
+		IAMPrincipal{Type: IAMPrincipalTypeAWS, ID: "345678901234"},
+		WithIAMRoleTrustContextKeys(map[string][]string{"sts:ExternalId": {"a1b2c3"}}),
+		WithIAMRoleTrustRequiredConditionKeys("sts:ExternalId"),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleTrustsPrincipal_OIDCSubject(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{
+			Role: &types.Role{
+				RoleName:                 &roleName,
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/testIam"),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(testIAMRoleTrustPolicy)),
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleTrustsPrincipal(fakeTest, ctx, client, roleName,
+		IAMPrincipal{Type: IAMPrincipalTypeFederated, ID: testIAMRoleTrustOIDCProvider},
+		WithIAMRoleTrustContextKeys(map[string][]string{
+			"token.actions.githubusercontent.com:sub": {"repo:example/app:ref:refs/heads/main"},
+			"token.actions.githubusercontent.com:aud": {"sts.amazonaws.com"},
+		}),
+		WithIAMRoleTrustRequiredConditionKeys("token.actions.githubusercontent.com:sub"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleDoesNotTrustPrincipal_OIDCWrongSubject(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{
+			Role: &types.Role{
+				RoleName:                 &roleName,
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/testIam"),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(testIAMRoleTrustPolicy)),
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleDoesNotTrustPrincipal(fakeTest, ctx, client, roleName,
+		IAMPrincipal{Type: IAMPrincipalTypeFederated, ID: testIAMRoleTrustOIDCProvider},
+		WithIAMRoleTrustContextKeys(map[string][]string{
+			"token.actions.githubusercontent.com:sub": {"repo:example/fork:pull_request"},
+			"token.actions.githubusercontent.com:aud": {"sts.amazonaws.com"},
+		}),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleDoesNotTrustPrincipal_TrustedAccount(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{
+			Role: &types.Role{
+				RoleName:                 &roleName,
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/testIam"),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(testIAMRoleTrustPolicy)),
+			},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleDoesNotTrustPrincipal(fakeTest, ctx, client, roleName, IAMPrincipal{Type: IAMPrincipalTypeAWS, ID: "arn:aws:iam::345678901234:user/admin"})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestIAMPolicyPrincipalMatches(t *testing.T) {
+	assert.True(t, iamPolicyPrincipalMatches("*", IAMPrincipal{Type: IAMPrincipalTypeAWS, ID: "123456789012"}))
+	assert.True(t, iamPolicyPrincipalMatches(map[string]interface{}{"AWS": "*"}, IAMPrincipal{Type: IAMPrincipalTypeAWS, ID: "123456789012"}))
+	assert.False(t, iamPolicyPrincipalMatches(map[string]interface{}{"AWS": "*"}, IAMPrincipal{Type: IAMPrincipalTypeService, ID: "ec2.amazonaws.com"}))
+	assert.True(t, iamPolicyPrincipalMatches(map[string]interface{}{"Service": []interface{}{"ecs-tasks.amazonaws.com", "ec2.amazonaws.com"}}, IAMPrincipal{Type: IAMPrincipalTypeService, ID: "ec2.amazonaws.com"}))
+}
+
+func TestIAMAWSPrincipalInAccount(t *testing.T) {
+	assert.True(t, iamAWSPrincipalInAccount("123456789012", "arn:aws:iam::123456789012:role/app"))
+	assert.True(t, iamAWSPrincipalInAccount("arn:aws:iam::123456789012:root", "123456789012"))
+	assert.False(t, iamAWSPrincipalInAccount("arn:aws:iam::123456789012:role/app", "arn:aws:iam::123456789012:role/other"))
+	assert.False(t, iamAWSPrincipalInAccount("123456789012", "arn:aws:iam::210987654321:role/app"))
+}
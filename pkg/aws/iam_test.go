@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	gomock "github.com/golang/mock/gomock"
@@ -288,3 +290,416 @@ func TestAssertIamRoleComponent_MaxDuration_Fail(t *testing.T) {
 	assert.True(t, fakeTest.Failed())
 
 }
+
+func TestAssertIAMRoleAllowsAction_AllowedByManagedPolicy(t *testing.T) {
+	// Setup
+	roleName := "testIam"
+	policyName := "inline"
+	policyARN := "arn:aws:iam::123456789012:policy/managed"
+	policyVersionID := "v3"
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		ListRolePolicies(ctx, &iam.ListRolePoliciesInput{RoleName: &roleName}, gomock.Any()).
+		Times(1).
+		Return(&iam.ListRolePoliciesOutput{PolicyNames: []string{policyName}}, nil)
+	client.EXPECT().
+		GetRolePolicy(ctx, &iam.GetRolePolicyInput{RoleName: &roleName, PolicyName: &policyName}).
+		Times(1).
+		Return(&iam.GetRolePolicyOutput{
+			PolicyDocument: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "sqs:SendMessage", "Resource": "*"}]}`)),
+		}, nil)
+	client.EXPECT().
+		ListAttachedRolePolicies(ctx, &iam.ListAttachedRolePoliciesInput{RoleName: &roleName}, gomock.Any()).
+		Times(1).
+		Return(&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []types.AttachedPolicy{{PolicyArn: &policyARN}}}, nil)
+	client.EXPECT().
+		GetPolicy(ctx, &iam.GetPolicyInput{PolicyArn: &policyARN}).
+		Times(1).
+		Return(&iam.GetPolicyOutput{Policy: &types.Policy{DefaultVersionId: &policyVersionID}}, nil)
+	client.EXPECT().
+		GetPolicyVersion(ctx, &iam.GetPolicyVersionInput{PolicyArn: &policyARN, VersionId: &policyVersionID}).
+		Times(1).
+		Return(&iam.GetPolicyVersionOutput{PolicyVersion: &types.PolicyVersion{
+			Document: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:Get*", "Resource": "arn:aws:s3:::artifacts/*"}]}`)),
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleAllowsAction(fakeTest, ctx, client, roleName, "s3:GetObject", "arn:aws:s3:::artifacts/app.zip")
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleAllowsAction_DeniedByInlinePolicy(t *testing.T) {
+	// Setup
+	roleName := "testIam"
+	policyName := "inline"
+	policyARN := "arn:aws:iam::123456789012:policy/managed"
+	policyVersionID := "v3"
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		ListRolePolicies(ctx, &iam.ListRolePoliciesInput{RoleName: &roleName}, gomock.Any()).
+		Times(1).
+		Return(&iam.ListRolePoliciesOutput{PolicyNames: []string{policyName}}, nil)
+	client.EXPECT().
+		GetRolePolicy(ctx, &iam.GetRolePolicyInput{RoleName: &roleName, PolicyName: &policyName}).
+		Times(1).
+		Return(&iam.GetRolePolicyOutput{
+			PolicyDocument: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Deny", "Action": "s3:*", "Resource": "arn:aws:s3:::artifacts/*"}]}`)),
+		}, nil)
+	client.EXPECT().
+		ListAttachedRolePolicies(ctx, &iam.ListAttachedRolePoliciesInput{RoleName: &roleName}, gomock.Any()).
+		Times(1).
+		Return(&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []types.AttachedPolicy{{PolicyArn: &policyARN}}}, nil)
+	client.EXPECT().
+		GetPolicy(ctx, &iam.GetPolicyInput{PolicyArn: &policyARN}).
+		Times(1).
+		Return(&iam.GetPolicyOutput{Policy: &types.Policy{DefaultVersionId: &policyVersionID}}, nil)
+	client.EXPECT().
+		GetPolicyVersion(ctx, &iam.GetPolicyVersionInput{PolicyArn: &policyARN, VersionId: &policyVersionID}).
+		Times(1).
+		Return(&iam.GetPolicyVersionOutput{PolicyVersion: &types.PolicyVersion{
+			Document: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:Get*", "Resource": "arn:aws:s3:::artifacts/*"}]}`)),
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleAllowsAction(fakeTest, ctx, client, roleName, "s3:GetObject", "arn:aws:s3:::artifacts/app.zip")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRolePermissionsBoundary_Matches(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	boundaryARN := "arn:aws:iam::123456789012:policy/boundary"
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName:            &roleName,
+			PermissionsBoundary: &types.AttachedPermissionsBoundary{PermissionsBoundaryArn: &boundaryARN},
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRolePermissionsBoundary(fakeTest, ctx, client, roleName, boundaryARN)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRolePermissionsBoundary_Missing(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{RoleName: &roleName}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRolePermissionsBoundary(fakeTest, ctx, client, roleName, "arn:aws:iam::123456789012:policy/boundary")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRolePath_Mismatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName: &roleName,
+			Path:     aws.String("/"),
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRolePath(fakeTest, ctx, client, roleName, "/service-role/")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleTags_Present(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName: &roleName,
+			Tags: []types.Tag{
+				{Key: aws.String("team"), Value: aws.String("platform")},
+				{Key: aws.String("cost-center"), Value: aws.String("1234")},
+				{Key: aws.String("env"), Value: aws.String("prod")},
+			},
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleTags(fakeTest, ctx, client, roleName, map[string]string{"team": "platform", "cost-center": ""})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleTags_MissingAndWrongValue(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName: &roleName,
+			Tags:     []types.Tag{{Key: aws.String("team"), Value: aws.String("data")}},
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleTags(fakeTest, ctx, client, roleName, map[string]string{"team": "platform", "cost-center": ""})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRolePath_MissingPath(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{RoleName: &roleName}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRolePath(fakeTest, ctx, client, roleName, "/service-role/")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleTags_SparseTags(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName: &roleName,
+			Tags: []types.Tag{
+				{Value: aws.String("orphan")},
+				{Key: aws.String("cost-center")},
+			},
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleTags(fakeTest, ctx, client, roleName, map[string]string{"cost-center": ""})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleUsedWithinDays_Recent(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName:     &roleName,
+			RoleLastUsed: &types.RoleLastUsed{LastUsedDate: aws.Time(time.Now().AddDate(0, 0, -3))},
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleUsedWithinDays(fakeTest, ctx, client, roleName, 30)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleUsedWithinDays_Stale(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName:     &roleName,
+			RoleLastUsed: &types.RoleLastUsed{LastUsedDate: aws.Time(time.Now().AddDate(0, 0, -45))},
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleUsedWithinDays(fakeTest, ctx, client, roleName, 30)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleUsedWithinDays_NeverUsed(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	roleName := "testIam"
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName:     &roleName,
+			RoleLastUsed: &types.RoleLastUsed{},
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleUsedWithinDays(fakeTest, ctx, client, roleName, 30)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleAllowsAction_DeniedByPermissionsBoundary(t *testing.T) {
+	// Setup
+	roleName := "testIam"
+	policyName := "inline"
+	policyARN := "arn:aws:iam::123456789012:policy/managed"
+	policyVersionID := "v3"
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		ListRolePolicies(ctx, &iam.ListRolePoliciesInput{RoleName: &roleName}, gomock.Any()).
+		Times(1).
+		Return(&iam.ListRolePoliciesOutput{PolicyNames: []string{policyName}}, nil)
+	client.EXPECT().
+		GetRolePolicy(ctx, &iam.GetRolePolicyInput{RoleName: &roleName, PolicyName: &policyName}).
+		Times(1).
+		Return(&iam.GetRolePolicyOutput{
+			PolicyDocument: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "sqs:SendMessage", "Resource": "*"}]}`)),
+		}, nil)
+	client.EXPECT().
+		ListAttachedRolePolicies(ctx, &iam.ListAttachedRolePoliciesInput{RoleName: &roleName}, gomock.Any()).
+		Times(1).
+		Return(&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []types.AttachedPolicy{{PolicyArn: &policyARN}}}, nil)
+	client.EXPECT().
+		GetPolicy(ctx, &iam.GetPolicyInput{PolicyArn: &policyARN}).
+		Times(1).
+		Return(&iam.GetPolicyOutput{Policy: &types.Policy{DefaultVersionId: &policyVersionID}}, nil)
+	client.EXPECT().
+		GetPolicyVersion(ctx, &iam.GetPolicyVersionInput{PolicyArn: &policyARN, VersionId: &policyVersionID}).
+		Times(1).
+		Return(&iam.GetPolicyVersionOutput{PolicyVersion: &types.PolicyVersion{
+			Document: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:Get*", "Resource": "arn:aws:s3:::artifacts/*"}]}`)),
+		}}, nil)
+	boundaryARN := "arn:aws:iam::123456789012:policy/boundary"
+	versionID := "v1"
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{
+			RoleName:            &roleName,
+			PermissionsBoundary: &types.AttachedPermissionsBoundary{PermissionsBoundaryArn: &boundaryARN},
+		}}, nil)
+	client.EXPECT().
+		GetPolicy(ctx, &iam.GetPolicyInput{PolicyArn: &boundaryARN}).
+		Times(1).
+		Return(&iam.GetPolicyOutput{Policy: &types.Policy{DefaultVersionId: &versionID}}, nil)
+	client.EXPECT().
+		GetPolicyVersion(ctx, &iam.GetPolicyVersionInput{PolicyArn: &boundaryARN, VersionId: &versionID}).
+		Times(1).
+		Return(&iam.GetPolicyVersionOutput{PolicyVersion: &types.PolicyVersion{
+			Document: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "sqs:*", "Resource": "*"}]}`)),
+		}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleAllowsAction(fakeTest, ctx, client, roleName, "s3:GetObject", "arn:aws:s3:::artifacts/app.zip", WithIAMRolePermissionsBoundaryEvaluation())
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertIAMRoleAllowsAction_NoPermissionsBoundary(t *testing.T) {
+	// Setup
+	roleName := "testIam"
+	policyName := "inline"
+	policyARN := "arn:aws:iam::123456789012:policy/managed"
+	policyVersionID := "v3"
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockIAMClient(ctrl)
+	client.EXPECT().
+		ListRolePolicies(ctx, &iam.ListRolePoliciesInput{RoleName: &roleName}, gomock.Any()).
+		Times(1).
+		Return(&iam.ListRolePoliciesOutput{PolicyNames: []string{policyName}}, nil)
+	client.EXPECT().
+		GetRolePolicy(ctx, &iam.GetRolePolicyInput{RoleName: &roleName, PolicyName: &policyName}).
+		Times(1).
+		Return(&iam.GetRolePolicyOutput{
+			PolicyDocument: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "sqs:SendMessage", "Resource": "*"}]}`)),
+		}, nil)
+	client.EXPECT().
+		ListAttachedRolePolicies(ctx, &iam.ListAttachedRolePoliciesInput{RoleName: &roleName}, gomock.Any()).
+		Times(1).
+		Return(&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []types.AttachedPolicy{{PolicyArn: &policyARN}}}, nil)
+	client.EXPECT().
+		GetPolicy(ctx, &iam.GetPolicyInput{PolicyArn: &policyARN}).
+		Times(1).
+		Return(&iam.GetPolicyOutput{Policy: &types.Policy{DefaultVersionId: &policyVersionID}}, nil)
+	client.EXPECT().
+		GetPolicyVersion(ctx, &iam.GetPolicyVersionInput{PolicyArn: &policyARN, VersionId: &policyVersionID}).
+		Times(1).
+		Return(&iam.GetPolicyVersionOutput{PolicyVersion: &types.PolicyVersion{
+			Document: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:Get*", "Resource": "arn:aws:s3:::artifacts/*"}]}`)),
+		}}, nil)
+	client.EXPECT().
+		GetRole(ctx, &iam.GetRoleInput{RoleName: &roleName}).
+		Times(1).
+		Return(&iam.GetRoleOutput{Role: &types.Role{RoleName: &roleName}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertIAMRoleAllowsAction(fakeTest, ctx, client, roleName, "s3:GetObject", "arn:aws:s3:::artifacts/app.zip", WithIAMRolePermissionsBoundaryEvaluation())
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
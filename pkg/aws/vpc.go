@@ -0,0 +1,358 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	vpcDefaultRouteIPv4CIDR         string = "0.0.0.0/0"
+	routeTableSubnetIDFilterName    string = "association.subnet-id"
+	routeTableMainFilterName        string = "association.main"
+	vpcIDFilterName                 string = "vpc-id"
+	internetGatewayIDPrefix         string = "igw-"
+	virtualPrivateGatewayIDPrefix   string = "vgw-"
+	vpcEndpointIDPrefix             string = "vpce-"
+	routeTableLocalGatewayIDValue   string = "local"
+	routeTableMainFilterValueIsMain string = "true"
+)
+
+// VPCRouteTargetType is the type of resource that a route sends traffic to.
+type VPCRouteTargetType string
+
+// The types of resource that a route can target.
+const (
+	VPCRouteTargetLocal                     VPCRouteTargetType = "local"
+	VPCRouteTargetInternetGateway           VPCRouteTargetType = "internet-gateway"
+	VPCRouteTargetEgressOnlyInternetGateway VPCRouteTargetType = "egress-only-internet-gateway"
+	VPCRouteTargetNATGateway                VPCRouteTargetType = "nat-gateway"
+	VPCRouteTargetTransitGateway            VPCRouteTargetType = "transit-gateway"
+	VPCRouteTargetVPCPeeringConnection      VPCRouteTargetType = "vpc-peering-connection"
+	VPCRouteTargetVirtualPrivateGateway     VPCRouteTargetType = "virtual-private-gateway"
+	VPCRouteTargetVPCEndpoint               VPCRouteTargetType = "vpc-endpoint"
+	VPCRouteTargetInstance                  VPCRouteTargetType = "instance"
+	VPCRouteTargetNetworkInterface          VPCRouteTargetType = "network-interface"
+	VPCRouteTargetLocalGateway              VPCRouteTargetType = "local-gateway"
+	VPCRouteTargetCarrierGateway            VPCRouteTargetType = "carrier-gateway"
+	VPCRouteTargetUnknown                   VPCRouteTargetType = "unknown"
+)
+
+// AssertVPCSubnetIsPrivate asserts that the route table used by a subnet has no route to an internet gateway. If the subnet has
+// no explicitly associated route table, the main route table of its VPC is checked.
+func AssertVPCSubnetIsPrivate(t *testing.T, ctx context.Context, client EC2Client, subnetID string) {
+	routeTable, err := getVPCSubnetRouteTableE(ctx, client, subnetID)
+	require.NoError(t, err)
+
+	violations := getVPCInternetGatewayRoutes(routeTable)
+	assert.Empty(t, violations, "Subnet '%s' uses route table '%s', which has routes to an internet gateway:\n%s", subnetID, *routeTable.RouteTableId, strings.Join(violations, "\n"))
+}
+
+// AssertVPCSubnetRoutesThroughNAT asserts that the default IPv4 route (0.0.0.0/0) of the route table used by a subnet sends
+// traffic to a NAT gateway. If the subnet has no explicitly associated route table, the main route table of its VPC is checked.
+func AssertVPCSubnetRoutesThroughNAT(t *testing.T, ctx context.Context, client EC2Client, subnetID string) {
+	routeTable, err := getVPCSubnetRouteTableE(ctx, client, subnetID)
+	require.NoError(t, err)
+
+	for _, route := range routeTable.Routes {
+		if getVPCRouteDestination(route) != vpcDefaultRouteIPv4CIDR || route.State == types.RouteStateBlackhole {
+			continue
+		}
+		targetType, targetID := getVPCRouteTarget(route)
+		assert.Equal(t, VPCRouteTargetNATGateway, targetType, "The default route of subnet '%s' targets '%s' rather than a NAT gateway.", subnetID, targetID)
+		return
+	}
+	assert.Fail(t, fmt.Sprintf("Route table '%s' used by subnet '%s' has no active default route.", *routeTable.RouteTableId, subnetID))
+}
+
+// AssertVPCRouteOptions is a struct used for functional options for the AssertVPCRouteExists method.
+type AssertVPCRouteOptions struct {
+	// The ID of the subnet whose route table is checked.
+	SubnetID string
+	// The ID of the route table that is checked.
+	RouteTableID string
+	// The type of resource that the route must target.
+	TargetType VPCRouteTargetType
+	// The ID of the resource that the route must target.
+	TargetID string
+}
+
+// AssertVPCRouteOptsFunc is a type used for functional options for the AssertVPCRouteExists method.
+type AssertVPCRouteOptsFunc func(*AssertVPCRouteOptions) error
+
+// WithVPCRouteSubnetID sets the subnet whose route table is checked. If the subnet has no explicitly associated route table, the
+// main route table of its VPC is checked.
+func WithVPCRouteSubnetID(subnetID string) AssertVPCRouteOptsFunc {
+	return func(opts *AssertVPCRouteOptions) error {
+		opts.SubnetID = subnetID
+		return nil
+	}
+}
+
+// WithVPCRouteTableID sets the route table that is checked.
+func WithVPCRouteTableID(routeTableID string) AssertVPCRouteOptsFunc {
+	return func(opts *AssertVPCRouteOptions) error {
+		opts.RouteTableID = routeTableID
+		return nil
+	}
+}
+
+// WithVPCRouteTargetType sets the type of resource that the route must target.
+func WithVPCRouteTargetType(targetType VPCRouteTargetType) AssertVPCRouteOptsFunc {
+	return func(opts *AssertVPCRouteOptions) error {
+		opts.TargetType = targetType
+		return nil
+	}
+}
+
+// WithVPCRouteTargetID sets the ID of the resource that the route must target, e.g. "tgw-0123456789abcdef0".
+func WithVPCRouteTargetID(targetID string) AssertVPCRouteOptsFunc {
+	return func(opts *AssertVPCRouteOptions) error {
+		opts.TargetID = targetID
+		return nil
+	}
+}
+
+/*
+AssertVPCRouteExists asserts that a route table has an active route for a destination, which may be an IPv4 CIDR, an IPv6 CIDR or
+a prefix list ID, and optionally that the route sends traffic to a given type of target or a given target. The route table is
+selected with exactly one of the WithVPCRouteSubnetID or WithVPCRouteTableID functional options.
+
+# Examples
+
+Assert that a subnet routes traffic for the corporate network through a specific transit gateway.
+
+	AssertVPCRouteExists(
+		t,
+		ctx,
+		client,
+		"10.0.0.0/8",
+		WithVPCRouteSubnetID("subnet-0123456789abcdef0"),
+		WithVPCRouteTargetType(VPCRouteTargetTransitGateway),
+		WithVPCRouteTargetID("tgw-0123456789abcdef0"),
+	)
+*/
+func AssertVPCRouteExists(t *testing.T, ctx context.Context, client EC2Client, destination string, optFns ...AssertVPCRouteOptsFunc) {
+	opts := &AssertVPCRouteOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+	require.True(t, (opts.SubnetID == "") != (opts.RouteTableID == ""), "Exactly one of a subnet ID or a route table ID must be provided.")
+
+	var routeTable types.RouteTable
+	var err error
+	if opts.SubnetID != "" {
+		routeTable, err = getVPCSubnetRouteTableE(ctx, client, opts.SubnetID)
+	} else {
+		routeTable, err = getVPCRouteTableByIDE(ctx, client, opts.RouteTableID)
+	}
+	require.NoError(t, err)
+
+	for _, route := range routeTable.Routes {
+		if getVPCRouteDestination(route) != destination {
+			continue
+		}
+		targetType, targetID := getVPCRouteTarget(route)
+		assert.NotEqual(t, types.RouteStateBlackhole, route.State, "Route for '%s' in route table '%s' is a blackhole.", destination, *routeTable.RouteTableId)
+		if opts.TargetType != "" {
+			assert.Equal(t, opts.TargetType, targetType, "Route for '%s' in route table '%s' does not target the right type of resource.", destination, *routeTable.RouteTableId)
+		}
+		if opts.TargetID != "" {
+			assert.Equal(t, opts.TargetID, targetID, "Route for '%s' in route table '%s' does not target the right resource.", destination, *routeTable.RouteTableId)
+		}
+		return
+	}
+	assert.Fail(t, fmt.Sprintf("Route table '%s' has no route for '%s'.", *routeTable.RouteTableId, destination))
+}
+
+// AssertVPCCIDRsDoNotOverlap asserts that no IPv4 CIDR block associated with one of the given VPCs overlaps a CIDR block associated
+// with another. If no VPC IDs are given, every VPC visible to the client is checked. The test failure lists every overlapping pair.
+func AssertVPCCIDRsDoNotOverlap(t *testing.T, ctx context.Context, client EC2Client, vpcIDs []string) {
+	vpcs, err := getVPCsE(ctx, client, &ec2.DescribeVpcsInput{VpcIds: vpcIDs})
+	require.NoError(t, err)
+
+	vpcCIDRs := make([][]string, len(vpcs))
+	for i, vpc := range vpcs {
+		vpcCIDRs[i] = getVPCIPv4CIDRs(vpc)
+	}
+
+	var violations []string
+	for i := range vpcs {
+		for j := i + 1; j < len(vpcs); j++ {
+			for _, cidrA := range vpcCIDRs[i] {
+				for _, cidrB := range vpcCIDRs[j] {
+					if cidrsOverlap(cidrA, cidrB) {
+						violations = append(violations, fmt.Sprintf("%s (%s) overlaps %s (%s)", *vpcs[i].VpcId, cidrA, *vpcs[j].VpcId, cidrB))
+					}
+				}
+			}
+		}
+	}
+
+	assert.Empty(t, violations, "VPCs with overlapping CIDR blocks were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// getVPCInternetGatewayRoutes returns a description of every active route in a route table that targets an internet gateway.
+func getVPCInternetGatewayRoutes(routeTable types.RouteTable) (routes []string) {
+	for _, route := range routeTable.Routes {
+		if route.State == types.RouteStateBlackhole {
+			continue
+		}
+		if targetType, targetID := getVPCRouteTarget(route); targetType == VPCRouteTargetInternetGateway {
+			routes = append(routes, fmt.Sprintf("%s -> %s", getVPCRouteDestination(route), targetID))
+		}
+	}
+	return
+}
+
+// getVPCIPv4CIDRs returns the IPv4 CIDR blocks currently associated with a VPC.
+func getVPCIPv4CIDRs(vpc types.Vpc) (cidrs []string) {
+	for _, association := range vpc.CidrBlockAssociationSet {
+		if association.CidrBlock == nil {
+			continue
+		}
+		if association.CidrBlockState != nil && association.CidrBlockState.State != types.VpcCidrBlockStateCodeAssociated {
+			continue
+		}
+		cidrs = appendUnique(cidrs, *association.CidrBlock)
+	}
+	if len(cidrs) == 0 && vpc.CidrBlock != nil {
+		cidrs = append(cidrs, *vpc.CidrBlock)
+	}
+	return
+}
+
+// getVPCRouteDestination returns the destination of a route, which is an IPv4 CIDR, an IPv6 CIDR or a prefix list ID.
+func getVPCRouteDestination(route types.Route) string {
+	switch {
+	case route.DestinationCidrBlock != nil:
+		return *route.DestinationCidrBlock
+	case route.DestinationIpv6CidrBlock != nil:
+		return *route.DestinationIpv6CidrBlock
+	case route.DestinationPrefixListId != nil:
+		return *route.DestinationPrefixListId
+	}
+	return ""
+}
+
+// getVPCRouteTarget returns the type and ID of the resource that a route sends traffic to.
+func getVPCRouteTarget(route types.Route) (VPCRouteTargetType, string) {
+	switch {
+	case route.NatGatewayId != nil:
+		return VPCRouteTargetNATGateway, *route.NatGatewayId
+	case route.TransitGatewayId != nil:
+		return VPCRouteTargetTransitGateway, *route.TransitGatewayId
+	case route.VpcPeeringConnectionId != nil:
+		return VPCRouteTargetVPCPeeringConnection, *route.VpcPeeringConnectionId
+	case route.EgressOnlyInternetGatewayId != nil:
+		return VPCRouteTargetEgressOnlyInternetGateway, *route.EgressOnlyInternetGatewayId
+	case route.CarrierGatewayId != nil:
+		return VPCRouteTargetCarrierGateway, *route.CarrierGatewayId
+	case route.LocalGatewayId != nil:
+		return VPCRouteTargetLocalGateway, *route.LocalGatewayId
+	case route.InstanceId != nil:
+		return VPCRouteTargetInstance, *route.InstanceId
+	case route.NetworkInterfaceId != nil:
+		return VPCRouteTargetNetworkInterface, *route.NetworkInterfaceId
+	case route.GatewayId != nil:
+		gatewayID := *route.GatewayId
+		switch {
+		case gatewayID == routeTableLocalGatewayIDValue:
+			return VPCRouteTargetLocal, gatewayID
+		case strings.HasPrefix(gatewayID, internetGatewayIDPrefix):
+			return VPCRouteTargetInternetGateway, gatewayID
+		case strings.HasPrefix(gatewayID, virtualPrivateGatewayIDPrefix):
+			return VPCRouteTargetVirtualPrivateGateway, gatewayID
+		case strings.HasPrefix(gatewayID, vpcEndpointIDPrefix):
+			return VPCRouteTargetVPCEndpoint, gatewayID
+		}
+		return VPCRouteTargetUnknown, gatewayID
+	}
+	return VPCRouteTargetUnknown, ""
+}
+
+// getVPCSubnetRouteTableE returns the route table used by a subnet. This is the route table explicitly associated with the subnet
+// if there is one, and otherwise the main route table of the subnet's VPC.
+func getVPCSubnetRouteTableE(ctx context.Context, client EC2Client, subnetID string) (types.RouteTable, error) {
+	routeTables, err := getVPCRouteTablesE(ctx, client, &ec2.DescribeRouteTablesInput{
+		Filters: CreateFiltersFromMap(map[string][]string{routeTableSubnetIDFilterName: {subnetID}}),
+	})
+	if err != nil {
+		return types.RouteTable{}, err
+	}
+	if len(routeTables) > 0 {
+		return routeTables[0], nil
+	}
+
+	subnets, err := getVPCSubnetsE(ctx, client, &ec2.DescribeSubnetsInput{SubnetIds: []string{subnetID}})
+	if err != nil {
+		return types.RouteTable{}, err
+	}
+	if len(subnets) == 0 || subnets[0].VpcId == nil {
+		return types.RouteTable{}, fmt.Errorf("subnet with ID '%s' was not found", subnetID)
+	}
+
+	vpcIDFilterKey := vpcIDFilterName
+	mainFilterKey := routeTableMainFilterName
+	routeTables, err = getVPCRouteTablesE(ctx, client, &ec2.DescribeRouteTablesInput{
+		Filters: []types.Filter{
+			{
+				Name:   &vpcIDFilterKey,
+				Values: []string{*subnets[0].VpcId},
+			},
+			{
+				Name:   &mainFilterKey,
+				Values: []string{routeTableMainFilterValueIsMain},
+			},
+		},
+	})
+	if err != nil {
+		return types.RouteTable{}, err
+	}
+	if len(routeTables) == 0 {
+		return types.RouteTable{}, fmt.Errorf("no route table was found for subnet '%s' in VPC '%s'", subnetID, *subnets[0].VpcId)
+	}
+	return routeTables[0], nil
+}
+
+// getVPCRouteTableByIDE returns the route table with the given ID.
+func getVPCRouteTableByIDE(ctx context.Context, client EC2Client, routeTableID string) (types.RouteTable, error) {
+	routeTables, err := getVPCRouteTablesE(ctx, client, &ec2.DescribeRouteTablesInput{RouteTableIds: []string{routeTableID}})
+	if err != nil {
+		return types.RouteTable{}, err
+	}
+	if len(routeTables) == 0 {
+		return types.RouteTable{}, fmt.Errorf("route table with ID '%s' was not found", routeTableID)
+	}
+	return routeTables[0], nil
+}
+
+// getVPCRouteTablesE returns every route table matching the given input, reading all pages of results.
+func getVPCRouteTablesE(ctx context.Context, client EC2Client, input *ec2.DescribeRouteTablesInput) ([]types.RouteTable, error) {
+	return collectPagesE(ctx, ec2.NewDescribeRouteTablesPaginator(client, input), func(output *ec2.DescribeRouteTablesOutput) []types.RouteTable {
+		return output.RouteTables
+	})
+}
+
+// getVPCSubnetsE returns every subnet matching the given input, reading all pages of results.
+func getVPCSubnetsE(ctx context.Context, client EC2Client, input *ec2.DescribeSubnetsInput) ([]types.Subnet, error) {
+	return collectPagesE(ctx, ec2.NewDescribeSubnetsPaginator(client, input), func(output *ec2.DescribeSubnetsOutput) []types.Subnet {
+		return output.Subnets
+	})
+}
+
+// getVPCsE returns every VPC matching the given input, reading all pages of results.
+func getVPCsE(ctx context.Context, client EC2Client, input *ec2.DescribeVpcsInput) ([]types.Vpc, error) {
+	return collectPagesE(ctx, ec2.NewDescribeVpcsPaginator(client, input), func(output *ec2.DescribeVpcsOutput) []types.Vpc {
+		return output.Vpcs
+	})
+}
@@ -0,0 +1,180 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2/json"
+)
+
+const (
+	vpcEndpointServiceNameFilterName string = "service-name"
+	vpcEndpointHTTPSPort             int32  = 443
+)
+
+/*
+AssertVPCEndpointExists asserts that a VPC has an available endpoint of the given type for a service.
+
+# Examples
+
+Assert that a VPC has a gateway endpoint for S3 and an interface endpoint for Secrets Manager.
+
+	AssertVPCEndpointExists(t, ctx, client, "vpc-0123456789abcdef0", "com.amazonaws.us-east-1.s3", types.VpcEndpointTypeGateway)
+	AssertVPCEndpointExists(t, ctx, client, "vpc-0123456789abcdef0", "com.amazonaws.us-east-1.secretsmanager", types.VpcEndpointTypeInterface)
+*/
+func AssertVPCEndpointExists(t *testing.T, ctx context.Context, client EC2Client, vpcID string, serviceName string, endpointType types.VpcEndpointType) {
+	endpoints, err := getVPCEndpointsE(ctx, client, vpcID, serviceName)
+	require.NoError(t, err)
+
+	for _, endpoint := range endpoints {
+		if endpoint.VpcEndpointType != endpointType {
+			continue
+		}
+		assert.Equal(t, types.StateAvailable, endpoint.State, "Endpoint '%s' for service '%s' in VPC '%s' is not available.", *endpoint.VpcEndpointId, serviceName, vpcID)
+		return
+	}
+	assert.Fail(t, fmt.Sprintf("VPC '%s' does not have a %s endpoint for service '%s'.", vpcID, endpointType, serviceName))
+}
+
+// AssertVPCEndpointPrivateDNSEnabled asserts that private DNS is enabled for the interface endpoint of a service in a VPC, so that
+// the default DNS name of the service resolves to the endpoint.
+func AssertVPCEndpointPrivateDNSEnabled(t *testing.T, ctx context.Context, client EC2Client, vpcID string, serviceName string) {
+	endpoint := getVPCEndpoint(t, ctx, client, vpcID, serviceName, types.VpcEndpointTypeInterface)
+
+	assert.True(t, endpoint.PrivateDnsEnabled != nil && *endpoint.PrivateDnsEnabled, "Endpoint '%s' for service '%s' in VPC '%s' does not have private DNS enabled.", *endpoint.VpcEndpointId, serviceName, vpcID)
+}
+
+/*
+AssertVPCEndpointPolicyAllows asserts that the policy of the endpoint of the given type for a service in a VPC allows a request. A
+request is allowed if at least one statement allows it and no statement explicitly denies it. The principal and context keys of the
+request are matched against the Principal and Condition elements of the policy, as they are by the AssertIAMPolicyAllowsRequest
+method.
+
+# Examples
+
+Assert that instances in a VPC can read objects from an artifacts bucket through the S3 gateway endpoint, but only from within the
+organization.
+
+	AssertVPCEndpointPolicyAllows(t, ctx, client, "vpc-0123456789abcdef0", "com.amazonaws.us-east-1.s3", types.VpcEndpointTypeGateway, IAMRequestContext{
+		Action:      "s3:GetObject",
+		Resource:    "arn:aws:s3:::artifacts/app.zip",
+		ContextKeys: map[string][]string{"aws:PrincipalOrgID": {"o-a1b2c3d4e5"}},
+	})
+*/
+func AssertVPCEndpointPolicyAllows(t *testing.T, ctx context.Context, client EC2Client, vpcID string, serviceName string, endpointType types.VpcEndpointType, request IAMRequestContext) {
+	policyDocument := getVPCEndpointPolicyDocument(t, ctx, client, vpcID, serviceName, endpointType)
+	decision, err := evaluateIAMPolicyDocuments([]PolicyDocument{policyDocument}, request)
+	require.NoError(t, err)
+
+	assert.Equal(t, iamPolicyDecisionAllow, decision, "Endpoint policy for service '%s' in VPC '%s' does not allow action '%s' on resource '%s'.", serviceName, vpcID, request.Action, request.Resource)
+}
+
+// AssertVPCEndpointPolicyDenies asserts that the policy of the endpoint of the given type for a service in a VPC does not allow a
+// request, either because a statement explicitly denies it or because no statement allows it.
+func AssertVPCEndpointPolicyDenies(t *testing.T, ctx context.Context, client EC2Client, vpcID string, serviceName string, endpointType types.VpcEndpointType, request IAMRequestContext) {
+	policyDocument := getVPCEndpointPolicyDocument(t, ctx, client, vpcID, serviceName, endpointType)
+	decision, err := evaluateIAMPolicyDocuments([]PolicyDocument{policyDocument}, request)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, iamPolicyDecisionAllow, decision, "Endpoint policy for service '%s' in VPC '%s' allows action '%s' on resource '%s'.", serviceName, vpcID, request.Action, request.Resource)
+}
+
+/*
+AssertVPCEndpointSecurityGroupsAllowHTTPS asserts that the security groups of the interface endpoint for a service in a VPC allow
+HTTPS traffic on port 443 from each of the given CIDR blocks. The test failure lists every CIDR block that is not allowed.
+
+# Examples
+
+Assert that the private subnets of a VPC can reach the Secrets Manager endpoint.
+
+	AssertVPCEndpointSecurityGroupsAllowHTTPS(t, ctx, client, "vpc-0123456789abcdef0", "com.amazonaws.us-east-1.secretsmanager", []string{"10.0.0.0/19", "10.0.32.0/19"})
+*/
+func AssertVPCEndpointSecurityGroupsAllowHTTPS(t *testing.T, ctx context.Context, client EC2Client, vpcID string, serviceName string, cidrs []string) {
+	endpoint := getVPCEndpoint(t, ctx, client, vpcID, serviceName, types.VpcEndpointTypeInterface)
+
+	var groupIDs []string
+	for _, group := range endpoint.Groups {
+		if group.GroupId != nil {
+			groupIDs = append(groupIDs, *group.GroupId)
+		}
+	}
+	require.NotEmpty(t, groupIDs, "Endpoint '%s' for service '%s' in VPC '%s' does not have any security groups.", *endpoint.VpcEndpointId, serviceName, vpcID)
+	securityGroups, err := getEC2SecurityGroupsE(ctx, client, CreateFiltersFromMap(map[string][]string{"group-id": groupIDs}))
+	require.NoError(t, err)
+
+	var permissions []types.IpPermission
+	for _, securityGroup := range securityGroups {
+		permissions = append(permissions, securityGroup.IpPermissions...)
+	}
+	port := vpcEndpointHTTPSPort
+	var violations []string
+	for _, cidr := range cidrs {
+		opts := EC2SecurityGroupRuleOptions{
+			Protocol: "tcp",
+			FromPort: &port,
+			ToPort:   &port,
+			CIDR:     cidr,
+		}
+		if !ec2IPPermissionsContainRule(permissions, opts) {
+			violations = append(violations, cidr)
+		}
+	}
+
+	assert.Empty(t, violations, "Security groups of endpoint '%s' for service '%s' in VPC '%s' do not allow HTTPS from: %v", *endpoint.VpcEndpointId, serviceName, vpcID, violations)
+}
+
+// getVPCEndpoint returns the endpoint of the given type for a service in a VPC, failing the test immediately if there is none. The
+// type is needed because a VPC can have both a gateway and an interface endpoint for services such as S3 and DynamoDB.
+func getVPCEndpoint(t *testing.T, ctx context.Context, client EC2Client, vpcID string, serviceName string, endpointType types.VpcEndpointType) types.VpcEndpoint {
+	endpoints, err := getVPCEndpointsE(ctx, client, vpcID, serviceName)
+	require.NoError(t, err)
+	var endpoint *types.VpcEndpoint
+	for i := range endpoints {
+		if endpoints[i].VpcEndpointType == endpointType {
+			endpoint = &endpoints[i]
+			break
+		}
+	}
+	require.NotNil(t, endpoint, "VPC '%s' does not have a %s endpoint for service '%s'.", vpcID, endpointType, serviceName)
+	return *endpoint
+}
+
+// getVPCEndpointPolicyDocument returns the parsed policy of the endpoint of the given type for a service in a VPC, failing the test
+// immediately if there is no endpoint or its policy cannot be parsed.
+func getVPCEndpointPolicyDocument(t *testing.T, ctx context.Context, client EC2Client, vpcID string, serviceName string, endpointType types.VpcEndpointType) PolicyDocument {
+	endpoint := getVPCEndpoint(t, ctx, client, vpcID, serviceName, endpointType)
+	require.NotNil(t, endpoint.PolicyDocument, "Endpoint '%s' for service '%s' in VPC '%s' does not have a policy.", *endpoint.VpcEndpointId, serviceName, vpcID)
+
+	var policyDocument PolicyDocument
+	err := json.Unmarshal([]byte(*endpoint.PolicyDocument), &policyDocument)
+	require.NoError(t, err)
+	return policyDocument
+}
+
+// getVPCEndpointsE returns every endpoint for a service in a VPC, reading all pages of results.
+func getVPCEndpointsE(ctx context.Context, client EC2Client, vpcID string, serviceName string) ([]types.VpcEndpoint, error) {
+	vpcIDFilterKey := vpcIDFilterName
+	serviceNameFilterKey := vpcEndpointServiceNameFilterName
+	input := &ec2.DescribeVpcEndpointsInput{
+		Filters: []types.Filter{
+			{
+				Name:   &vpcIDFilterKey,
+				Values: []string{vpcID},
+			},
+			{
+				Name:   &serviceNameFilterKey,
+				Values: []string{serviceName},
+			},
+		},
+	}
+	return collectPagesE(ctx, ec2.NewDescribeVpcEndpointsPaginator(client, input), func(output *ec2.DescribeVpcEndpointsOutput) []types.VpcEndpoint {
+		return output.VpcEndpoints
+	})
+}
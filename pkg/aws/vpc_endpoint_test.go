@@ -0,0 +1,353 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testVPCEndpointPolicy string = `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{"Effect": "Allow", "Principal": "*", "Action": ["s3:Get*", "s3:ListBucket"], "Resource": "arn:aws:s3:::artifacts*"},
+			{"Effect": "Deny", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::artifacts/secrets/*"}
+		]
+	}`
+)
+
+func newTestVPCEndpoint() types.VpcEndpoint {
+	return types.VpcEndpoint{
+		VpcEndpointId:     aws.String("vpce-123456"),
+		VpcId:             aws.String("vpc-123456"),
+		ServiceName:       aws.String("com.amazonaws.us-east-1.s3"),
+		VpcEndpointType:   types.VpcEndpointTypeInterface,
+		State:             types.StateAvailable,
+		PrivateDnsEnabled: aws.Bool(true),
+		PolicyDocument:    aws.String(testVPCEndpointPolicy),
+		Groups: []types.SecurityGroupIdentifier{
+			{GroupId: aws.String("sg-123456")},
+		},
+	}
+}
+
+func TestAssertVPCEndpointExists_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{newTestVPCEndpoint()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointExists(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3", types.VpcEndpointTypeInterface)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCEndpointExists_WrongType(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{newTestVPCEndpoint()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointExists(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3", types.VpcEndpointTypeGateway)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCEndpointPrivateDNSEnabled_NoMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	endpoint := newTestVPCEndpoint()
+	endpoint.PrivateDnsEnabled = aws.Bool(false)
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{endpoint}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointPrivateDNSEnabled(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCEndpointPrivateDNSEnabled_GatewayAndInterface(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	gatewayEndpoint := newTestVPCEndpoint()
+	gatewayEndpoint.VpcEndpointId = aws.String("vpce-654321")
+	gatewayEndpoint.VpcEndpointType = types.VpcEndpointTypeGateway
+	gatewayEndpoint.PrivateDnsEnabled = aws.Bool(false)
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{gatewayEndpoint, newTestVPCEndpoint()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointPrivateDNSEnabled(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3")
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCEndpointPolicyAllows_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{newTestVPCEndpoint()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointPolicyAllows(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3", types.VpcEndpointTypeInterface, IAMRequestContext{
+		Action:   "s3:GetObject",
+		Resource: "arn:aws:s3:::artifacts/builds/app.zip",
+	})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCEndpointPolicyAllows_ContextKeys(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	endpoint := newTestVPCEndpoint()
+	endpoint.VpcEndpointType = types.VpcEndpointTypeGateway
+	endpoint.PolicyDocument = aws.String(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": "*",
+				"Action": "s3:GetObject",
+				"Resource": "arn:aws:s3:::artifacts/*",
+				"Condition": {"StringEquals": {"aws:PrincipalOrgID": "o-a1b2c3d4e5"}}
+			}
+		]
+	}`)
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{endpoint}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointPolicyAllows(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3", types.VpcEndpointTypeGateway, IAMRequestContext{
+		Action:      "s3:GetObject",
+		Resource:    "arn:aws:s3:::artifacts/app.zip",
+		ContextKeys: map[string][]string{"aws:PrincipalOrgID": {"o-a1b2c3d4e5"}},
+	})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCEndpointPolicyDenies_ExplicitDeny(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{newTestVPCEndpoint()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointPolicyDenies(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3", types.VpcEndpointTypeInterface, IAMRequestContext{
+		Action:   "s3:GetObject",
+		Resource: "arn:aws:s3:::artifacts/secrets/key",
+	})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCEndpointPolicyDenies_Allowed(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{newTestVPCEndpoint()}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointPolicyDenies(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3", types.VpcEndpointTypeInterface, IAMRequestContext{
+		Action:   "s3:ListBucket",
+		Resource: "arn:aws:s3:::artifacts",
+	})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCEndpointSecurityGroupsAllowHTTPS_PartialMatch(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeVpcEndpoints(ctx, &ec2.DescribeVpcEndpointsInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []string{"vpc-123456"},
+				},
+				{
+					Name:   aws.String("service-name"),
+					Values: []string{"com.amazonaws.us-east-1.s3"},
+				},
+			},
+		}).
+		Times(1).
+		Return(&ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []types.VpcEndpoint{newTestVPCEndpoint()}}, nil)
+	input := &ec2.DescribeSecurityGroupsInput{
+		Filters: CreateFiltersFromMap(map[string][]string{"group-id": {"sg-123456"}}),
+	}
+	clientMock.EXPECT().DescribeSecurityGroups(ctx, input).Times(1).Return(&ec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []types.SecurityGroup{
+			{
+				GroupId: aws.String("sg-123456"),
+				IpPermissions: []types.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int32(443),
+						ToPort:     aws.Int32(443),
+						IpRanges:   []types.IpRange{{CidrIp: aws.String("10.0.0.0/16")}},
+					},
+				},
+			},
+		},
+	}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCEndpointSecurityGroupsAllowHTTPS(fakeTest, ctx, clientMock, "vpc-123456", "com.amazonaws.us-east-1.s3", []string{"10.0.32.0/19", "10.1.0.0/16"})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
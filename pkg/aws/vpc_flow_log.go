@@ -0,0 +1,204 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flowLogResourceIDFilterName string = "resource-id"
+	flowLogStatusActive         string = "ACTIVE"
+	flowLogDeliverStatusFailed  string = "FAILED"
+	// flowLogResourceIDBatchSize is the number of resource IDs given in each request, within the limit EC2 places on filter values.
+	flowLogResourceIDBatchSize int = 200
+	// flowLogDefaultFormat is the format that AWS uses for flow logs that are created without a custom format.
+	flowLogDefaultFormat string = "${version} ${account-id} ${interface-id} ${srcaddr} ${dstaddr} ${srcport} ${dstport} ${protocol} ${packets} ${bytes} ${start} ${end} ${action} ${log-status}"
+)
+
+// AssertVPCFlowLogsEnabledOptions is a struct used for functional options for the AssertVPCFlowLogsEnabled method.
+type AssertVPCFlowLogsEnabledOptions struct {
+	// The IDs of the VPCs, subnets or network interfaces that must have flow logs. Defaults to every VPC in the account and region.
+	ResourceIDs []string
+	// The traffic type that flow logs must capture. Flow logs that capture all traffic satisfy any traffic type.
+	TrafficType types.TrafficType
+	// The type of destination that flow logs must deliver to.
+	DestinationType types.LogDestinationType
+	// Fields that the log format must include, e.g. "vpc-id" or "${tcp-flags}".
+	FormatFields []string
+}
+
+// AssertVPCFlowLogsEnabledOptsFunc is a type used for functional options for the AssertVPCFlowLogsEnabled method.
+type AssertVPCFlowLogsEnabledOptsFunc func(*AssertVPCFlowLogsEnabledOptions) error
+
+// WithVPCFlowLogsResourceIDs checks the given VPCs, subnets or network interfaces instead of every VPC.
+func WithVPCFlowLogsResourceIDs(resourceIDs ...string) AssertVPCFlowLogsEnabledOptsFunc {
+	return func(opts *AssertVPCFlowLogsEnabledOptions) error {
+		opts.ResourceIDs = resourceIDs
+		return nil
+	}
+}
+
+// WithVPCFlowLogsTrafficType requires flow logs to capture the given type of traffic.
+func WithVPCFlowLogsTrafficType(trafficType types.TrafficType) AssertVPCFlowLogsEnabledOptsFunc {
+	return func(opts *AssertVPCFlowLogsEnabledOptions) error {
+		opts.TrafficType = trafficType
+		return nil
+	}
+}
+
+// WithVPCFlowLogsDestinationType requires flow logs to deliver to the given type of destination, e.g. CloudWatch Logs or S3.
+func WithVPCFlowLogsDestinationType(destinationType types.LogDestinationType) AssertVPCFlowLogsEnabledOptsFunc {
+	return func(opts *AssertVPCFlowLogsEnabledOptions) error {
+		opts.DestinationType = destinationType
+		return nil
+	}
+}
+
+// WithVPCFlowLogsFormatFields requires the log format of flow logs to include each of the given fields. Fields may be given either
+// as a bare name (e.g. "vpc-id") or in the form used by the log format (e.g. "${vpc-id}").
+func WithVPCFlowLogsFormatFields(fields ...string) AssertVPCFlowLogsEnabledOptsFunc {
+	return func(opts *AssertVPCFlowLogsEnabledOptions) error {
+		for _, field := range fields {
+			field = strings.TrimSuffix(strings.TrimPrefix(field, "${"), "}")
+			if field == "" {
+				return fmt.Errorf("flow log format fields must not be empty")
+			}
+			opts.FormatFields = append(opts.FormatFields, fmt.Sprintf("${%s}", field))
+		}
+		return nil
+	}
+}
+
+/*
+AssertVPCFlowLogsEnabled asserts that every VPC, or every one of a given set of VPCs, subnets or network interfaces, has an active
+flow log that meets the given requirements. A flow log only counts for the resource it is attached to; for example, a flow log on a
+VPC does not satisfy the check for one of its subnets. The test failure lists every resource without a compliant flow log, along
+with the reasons that any of its flow logs do not comply.
+
+# Examples
+
+Assert that every VPC delivers logs of all traffic to S3, including the VPC and subnet IDs.
+
+	AssertVPCFlowLogsEnabled(t, ctx, client,
+		WithVPCFlowLogsTrafficType(types.TrafficTypeAll),
+		WithVPCFlowLogsDestinationType(types.LogDestinationTypeS3),
+		WithVPCFlowLogsFormatFields("vpc-id", "subnet-id"),
+	)
+
+Assert that two subnets have active flow logs of any kind.
+
+	AssertVPCFlowLogsEnabled(t, ctx, client, WithVPCFlowLogsResourceIDs("subnet-0123456789abcdef0", "subnet-0123456789abcdef1"))
+*/
+func AssertVPCFlowLogsEnabled(t *testing.T, ctx context.Context, client EC2Client, optFns ...AssertVPCFlowLogsEnabledOptsFunc) {
+	opts := &AssertVPCFlowLogsEnabledOptions{}
+	for _, optFn := range optFns {
+		err := optFn(opts)
+		require.Nil(t, err, "Optional function threw an unexpected error.")
+	}
+
+	resourceIDs := opts.ResourceIDs
+	if len(resourceIDs) == 0 {
+		vpcs, err := getVPCsE(ctx, client, &ec2.DescribeVpcsInput{})
+		require.NoError(t, err)
+		for _, vpc := range vpcs {
+			resourceIDs = append(resourceIDs, *vpc.VpcId)
+		}
+	}
+	if len(resourceIDs) == 0 {
+		return
+	}
+
+	flowLogs, err := getVPCFlowLogsForResourcesE(ctx, client, resourceIDs)
+	require.NoError(t, err)
+	flowLogsByResource := make(map[string][]types.FlowLog)
+	for _, flowLog := range flowLogs {
+		if flowLog.ResourceId != nil {
+			flowLogsByResource[*flowLog.ResourceId] = append(flowLogsByResource[*flowLog.ResourceId], flowLog)
+		}
+	}
+
+	var violations []string
+	for _, resourceID := range resourceIDs {
+		resourceFlowLogs := flowLogsByResource[resourceID]
+		if len(resourceFlowLogs) == 0 {
+			violations = append(violations, fmt.Sprintf("%s: no flow log is attached", resourceID))
+			continue
+		}
+		var resourceViolations []string
+		for _, flowLog := range resourceFlowLogs {
+			flowLogViolations := getVPCFlowLogViolations(flowLog, opts)
+			if len(flowLogViolations) == 0 {
+				resourceViolations = nil
+				break
+			}
+			if flowLog.FlowLogId != nil {
+				flowLogViolations = prefixViolations(*flowLog.FlowLogId, flowLogViolations)
+			}
+			resourceViolations = append(resourceViolations, flowLogViolations...)
+		}
+		violations = append(violations, prefixViolations(resourceID, resourceViolations)...)
+	}
+
+	assert.Empty(t, violations, "Resources without a compliant flow log were found:\n%s", strings.Join(violations, "\n"))
+}
+
+// getVPCFlowLogViolations returns a description of every way in which a flow log does not meet the requirements in the options.
+func getVPCFlowLogViolations(flowLog types.FlowLog, opts *AssertVPCFlowLogsEnabledOptions) (violations []string) {
+	if flowLog.FlowLogStatus == nil || *flowLog.FlowLogStatus != flowLogStatusActive {
+		violations = append(violations, "flow log is not active")
+	}
+	if flowLog.DeliverLogsStatus != nil && *flowLog.DeliverLogsStatus == flowLogDeliverStatusFailed {
+		violations = append(violations, "log delivery is failing")
+	}
+	if opts.TrafficType != "" && flowLog.TrafficType != types.TrafficTypeAll && flowLog.TrafficType != opts.TrafficType {
+		violations = append(violations, fmt.Sprintf("traffic type is '%s', not '%s'", flowLog.TrafficType, opts.TrafficType))
+	}
+	if opts.DestinationType != "" && flowLog.LogDestinationType != opts.DestinationType {
+		violations = append(violations, fmt.Sprintf("destination type is '%s', not '%s'", flowLog.LogDestinationType, opts.DestinationType))
+	}
+	logFormat := flowLogDefaultFormat
+	if flowLog.LogFormat != nil {
+		logFormat = *flowLog.LogFormat
+	}
+	for _, field := range opts.FormatFields {
+		if !stringInSlice(field, strings.Fields(logFormat)) {
+			violations = append(violations, fmt.Sprintf("log format does not include field '%s'", field))
+		}
+	}
+	return
+}
+
+// getVPCFlowLogsForResourcesE returns every flow log attached to any of the given resources. The resource IDs are sent in batches,
+// as EC2 limits the number of values in a filter.
+func getVPCFlowLogsForResourcesE(ctx context.Context, client EC2Client, resourceIDs []string) (flowLogs []types.FlowLog, err error) {
+	for start := 0; start < len(resourceIDs); start += flowLogResourceIDBatchSize {
+		end := start + flowLogResourceIDBatchSize
+		if end > len(resourceIDs) {
+			end = len(resourceIDs)
+		}
+		batchFlowLogs, err := getVPCFlowLogsE(ctx, client, &ec2.DescribeFlowLogsInput{
+			Filter: CreateFiltersFromMap(map[string][]string{flowLogResourceIDFilterName: resourceIDs[start:end]}),
+		})
+		if err != nil {
+			return nil, err
+		}
+		flowLogs = append(flowLogs, batchFlowLogs...)
+	}
+	return
+}
+
+// getVPCFlowLogsE returns every flow log matching the given input, reading all pages of results.
+func getVPCFlowLogsE(ctx context.Context, client EC2Client, input *ec2.DescribeFlowLogsInput) ([]types.FlowLog, error) {
+	return collectPagesE(ctx, ec2.NewDescribeFlowLogsPaginator(client, input), func(output *ec2.DescribeFlowLogsOutput) []types.FlowLog {
+		return output.FlowLogs
+	})
+}
@@ -0,0 +1,190 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVPCFlowLog(resourceID string) types.FlowLog {
+	return types.FlowLog{
+		FlowLogId:          aws.String("fl-" + resourceID),
+		ResourceId:         aws.String(resourceID),
+		FlowLogStatus:      aws.String("ACTIVE"),
+		DeliverLogsStatus:  aws.String("SUCCESS"),
+		TrafficType:        types.TrafficTypeAll,
+		LogDestinationType: types.LogDestinationTypeS3,
+		LogFormat:          aws.String("${version} ${vpc-id} ${subnet-id} ${srcaddr} ${dstaddr} ${action}"),
+	}
+}
+
+func TestAssertVPCFlowLogsEnabled_AllVPCs(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeFlowLogs(ctx, &ec2.DescribeFlowLogsInput{
+			Filter: CreateFiltersFromMap(map[string][]string{"resource-id": {"vpc-123456", "vpc-654321"}}),
+		}).
+		Times(1).
+		Return(&ec2.DescribeFlowLogsOutput{
+			FlowLogs: []types.FlowLog{
+				newTestVPCFlowLog("vpc-123456"),
+				newTestVPCFlowLog("vpc-654321"),
+			},
+		}, nil)
+	clientMock.EXPECT().DescribeVpcs(ctx, &ec2.DescribeVpcsInput{}).Times(1).Return(&ec2.DescribeVpcsOutput{
+		Vpcs: []types.Vpc{
+			{VpcId: aws.String("vpc-123456")},
+			{VpcId: aws.String("vpc-654321")},
+		},
+	}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCFlowLogsEnabled(fakeTest, ctx, clientMock,
+		WithVPCFlowLogsTrafficType(types.TrafficTypeReject),
+		WithVPCFlowLogsDestinationType(types.LogDestinationTypeS3),
+		WithVPCFlowLogsFormatFields("vpc-id", "${subnet-id}"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCFlowLogsEnabled_BatchesResourceIDs(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	var vpcs []types.Vpc
+	var vpcIDs []string
+	var flowLogs []types.FlowLog
+	for i := 0; i < 250; i++ {
+		vpcID := fmt.Sprintf("vpc-%06d", i)
+		vpcs = append(vpcs, types.Vpc{VpcId: aws.String(vpcID)})
+		vpcIDs = append(vpcIDs, vpcID)
+		flowLogs = append(flowLogs, newTestVPCFlowLog(vpcID))
+	}
+	clientMock.EXPECT().DescribeVpcs(context.Background(), &ec2.DescribeVpcsInput{}).Times(1).Return(&ec2.DescribeVpcsOutput{Vpcs: vpcs}, nil)
+	clientMock.EXPECT().
+		DescribeFlowLogs(context.Background(), &ec2.DescribeFlowLogsInput{Filter: CreateFiltersFromMap(map[string][]string{"resource-id": vpcIDs[:200]})}).
+		Times(1).
+		Return(&ec2.DescribeFlowLogsOutput{FlowLogs: flowLogs[:200]}, nil)
+	clientMock.EXPECT().
+		DescribeFlowLogs(context.Background(), &ec2.DescribeFlowLogsInput{Filter: CreateFiltersFromMap(map[string][]string{"resource-id": vpcIDs[200:]})}).
+		Times(1).
+		Return(&ec2.DescribeFlowLogsOutput{FlowLogs: flowLogs[200:]}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCFlowLogsEnabled(fakeTest, context.Background(), clientMock)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCFlowLogsEnabled_MissingFlowLogID(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	flowLog := newTestVPCFlowLog("vpc-123456")
+	flowLog.FlowLogId = nil
+	flowLog.FlowLogStatus = aws.String("INACTIVE")
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeFlowLogs(ctx, &ec2.DescribeFlowLogsInput{
+			Filter: CreateFiltersFromMap(map[string][]string{"resource-id": {"vpc-123456"}}),
+		}).
+		Times(1).
+		Return(&ec2.DescribeFlowLogsOutput{
+			FlowLogs: []types.FlowLog{flowLog},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCFlowLogsEnabled(fakeTest, ctx, clientMock, WithVPCFlowLogsResourceIDs("vpc-123456"))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCFlowLogsEnabled_MissingFlowLog(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeFlowLogs(ctx, &ec2.DescribeFlowLogsInput{
+			Filter: CreateFiltersFromMap(map[string][]string{"resource-id": {"subnet-123456", "subnet-654321"}}),
+		}).
+		Times(1).
+		Return(&ec2.DescribeFlowLogsOutput{
+			FlowLogs: []types.FlowLog{newTestVPCFlowLog("subnet-123456")},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCFlowLogsEnabled(fakeTest, ctx, clientMock, WithVPCFlowLogsResourceIDs("subnet-123456", "subnet-654321"))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCFlowLogsEnabled_OneCompliantFlowLog(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	inactive := newTestVPCFlowLog("vpc-123456")
+	inactive.FlowLogId = aws.String("fl-inactive")
+	inactive.DeliverLogsStatus = aws.String("FAILED")
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	clientMock.EXPECT().
+		DescribeFlowLogs(ctx, &ec2.DescribeFlowLogsInput{
+			Filter: CreateFiltersFromMap(map[string][]string{"resource-id": {"vpc-123456"}}),
+		}).
+		Times(1).
+		Return(&ec2.DescribeFlowLogsOutput{
+			FlowLogs: []types.FlowLog{inactive, newTestVPCFlowLog("vpc-123456")},
+		}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCFlowLogsEnabled(fakeTest, ctx, clientMock, WithVPCFlowLogsResourceIDs("vpc-123456"))
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestGetVPCFlowLogViolations_AllViolations(t *testing.T) {
+	// Setup
+	flowLog := newTestVPCFlowLog("vpc-123456")
+	flowLog.FlowLogStatus = aws.String("ERROR")
+	flowLog.DeliverLogsStatus = aws.String("FAILED")
+	flowLog.TrafficType = types.TrafficTypeAccept
+	flowLog.LogFormat = nil
+	opts := &AssertVPCFlowLogsEnabledOptions{}
+	for _, optFn := range []AssertVPCFlowLogsEnabledOptsFunc{
+		WithVPCFlowLogsTrafficType(types.TrafficTypeReject),
+		WithVPCFlowLogsDestinationType(types.LogDestinationTypeCloudWatchLogs),
+		WithVPCFlowLogsFormatFields("vpc-id"),
+	} {
+		assert.NoError(t, optFn(opts))
+	}
+
+	// Execute
+	violations := getVPCFlowLogViolations(flowLog, opts)
+
+	// Assert
+	assert.Len(t, violations, 5)
+}
@@ -0,0 +1,258 @@
+// Copyright (c) WarnerMedia Direct, LLC. All rights reserved. Licensed under the MIT license.
+// See the LICENSE file for license information.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/hbocodelabs/infratest/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVPCRouteTable(routes ...types.Route) types.RouteTable {
+	return types.RouteTable{
+		RouteTableId: aws.String("rtb-123456"),
+		Routes: append([]types.Route{
+			{
+				DestinationCidrBlock: aws.String("10.0.0.0/16"),
+				GatewayId:            aws.String("local"),
+				State:                types.RouteStateActive,
+			},
+		}, routes...),
+	}
+}
+
+func newTestVPCSubnetRouteTableClient(t *testing.T, routeTable types.RouteTable) *mock.MockEC2Client {
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	input := &ec2.DescribeRouteTablesInput{
+		Filters: CreateFiltersFromMap(map[string][]string{"association.subnet-id": {"subnet-123456"}}),
+	}
+	clientMock.EXPECT().
+		DescribeRouteTables(context.Background(), input).
+		Times(1).
+		Return(&ec2.DescribeRouteTablesOutput{RouteTables: []types.RouteTable{routeTable}}, nil)
+	return clientMock
+}
+
+func TestAssertVPCSubnetIsPrivate_Private(t *testing.T) {
+	// Setup
+	clientMock := newTestVPCSubnetRouteTableClient(t, newTestVPCRouteTable(types.Route{
+		DestinationCidrBlock: aws.String("0.0.0.0/0"),
+		NatGatewayId:         aws.String("nat-123456"),
+		State:                types.RouteStateActive,
+	}))
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCSubnetIsPrivate(fakeTest, context.Background(), clientMock, "subnet-123456")
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCSubnetIsPrivate_Public(t *testing.T) {
+	// Setup
+	clientMock := newTestVPCSubnetRouteTableClient(t, newTestVPCRouteTable(types.Route{
+		DestinationIpv6CidrBlock: aws.String("::/0"),
+		GatewayId:                aws.String("igw-123456"),
+		State:                    types.RouteStateActive,
+	}))
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCSubnetIsPrivate(fakeTest, context.Background(), clientMock, "subnet-123456")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCSubnetIsPrivate_MainRouteTable(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	associationInput := &ec2.DescribeRouteTablesInput{
+		Filters: CreateFiltersFromMap(map[string][]string{"association.subnet-id": {"subnet-123456"}}),
+	}
+	mainInput := &ec2.DescribeRouteTablesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: []string{"vpc-123456"},
+			},
+			{
+				Name:   aws.String("association.main"),
+				Values: []string{"true"},
+			},
+		},
+	}
+	routeTable := newTestVPCRouteTable(types.Route{
+		DestinationCidrBlock: aws.String("0.0.0.0/0"),
+		GatewayId:            aws.String("igw-123456"),
+		State:                types.RouteStateActive,
+	})
+	gomock.InOrder(
+		clientMock.EXPECT().DescribeRouteTables(ctx, associationInput).Times(1).Return(&ec2.DescribeRouteTablesOutput{}, nil),
+		clientMock.EXPECT().
+			DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{SubnetIds: []string{"subnet-123456"}}).
+			Times(1).
+			Return(&ec2.DescribeSubnetsOutput{Subnets: []types.Subnet{{SubnetId: aws.String("subnet-123456"), VpcId: aws.String("vpc-123456")}}}, nil),
+		clientMock.EXPECT().DescribeRouteTables(ctx, mainInput).Times(1).Return(&ec2.DescribeRouteTablesOutput{RouteTables: []types.RouteTable{routeTable}}, nil),
+	)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCSubnetIsPrivate(fakeTest, ctx, clientMock, "subnet-123456")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCSubnetRoutesThroughNAT_NAT(t *testing.T) {
+	// Setup
+	clientMock := newTestVPCSubnetRouteTableClient(t, newTestVPCRouteTable(types.Route{
+		DestinationCidrBlock: aws.String("0.0.0.0/0"),
+		NatGatewayId:         aws.String("nat-123456"),
+		State:                types.RouteStateActive,
+	}))
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCSubnetRoutesThroughNAT(fakeTest, context.Background(), clientMock, "subnet-123456")
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCSubnetRoutesThroughNAT_NoDefaultRoute(t *testing.T) {
+	// Setup
+	clientMock := newTestVPCSubnetRouteTableClient(t, newTestVPCRouteTable(types.Route{
+		DestinationCidrBlock: aws.String("0.0.0.0/0"),
+		NatGatewayId:         aws.String("nat-123456"),
+		State:                types.RouteStateBlackhole,
+	}))
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCSubnetRoutesThroughNAT(fakeTest, context.Background(), clientMock, "subnet-123456")
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCRouteExists_Match(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	routeTable := newTestVPCRouteTable(types.Route{
+		DestinationCidrBlock: aws.String("10.0.0.0/8"),
+		TransitGatewayId:     aws.String("tgw-123456"),
+		State:                types.RouteStateActive,
+	})
+	clientMock.EXPECT().
+		DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{RouteTableIds: []string{"rtb-123456"}}).
+		Times(1).
+		Return(&ec2.DescribeRouteTablesOutput{RouteTables: []types.RouteTable{routeTable}}, nil)
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCRouteExists(fakeTest, ctx, clientMock, "10.0.0.0/8",
+		WithVPCRouteTableID("rtb-123456"),
+		WithVPCRouteTargetType(VPCRouteTargetTransitGateway),
+		WithVPCRouteTargetID("tgw-123456"),
+	)
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCRouteExists_WrongTarget(t *testing.T) {
+	// Setup
+	clientMock := newTestVPCSubnetRouteTableClient(t, newTestVPCRouteTable(types.Route{
+		DestinationCidrBlock:   aws.String("10.0.0.0/8"),
+		VpcPeeringConnectionId: aws.String("pcx-123456"),
+		State:                  types.RouteStateActive,
+	}))
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCRouteExists(fakeTest, context.Background(), clientMock, "10.0.0.0/8",
+		WithVPCRouteSubnetID("subnet-123456"),
+		WithVPCRouteTargetType(VPCRouteTargetTransitGateway),
+	)
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func TestAssertVPCRouteExists_Missing(t *testing.T) {
+	// Setup
+	clientMock := newTestVPCSubnetRouteTableClient(t, newTestVPCRouteTable())
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCRouteExists(fakeTest, context.Background(), clientMock, "pl-123456", WithVPCRouteSubnetID("subnet-123456"))
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}
+
+func newTestVPCCIDRsClient(t *testing.T, cidr string) *mock.MockEC2Client {
+	ctrl := gomock.NewController(t)
+	clientMock := mock.NewMockEC2Client(ctrl)
+	output := &ec2.DescribeVpcsOutput{
+		Vpcs: []types.Vpc{
+			{
+				VpcId:     aws.String("vpc-a"),
+				CidrBlock: aws.String("10.0.0.0/16"),
+			},
+			{
+				VpcId: aws.String("vpc-b"),
+				CidrBlockAssociationSet: []types.VpcCidrBlockAssociation{
+					{
+						CidrBlock:      aws.String("192.168.0.0/16"),
+						CidrBlockState: &types.VpcCidrBlockState{State: types.VpcCidrBlockStateCodeAssociated},
+					},
+					{
+						CidrBlock:      aws.String(cidr),
+						CidrBlockState: &types.VpcCidrBlockState{State: types.VpcCidrBlockStateCodeAssociated},
+					},
+				},
+			},
+		},
+	}
+	clientMock.EXPECT().DescribeVpcs(context.Background(), &ec2.DescribeVpcsInput{VpcIds: []string{"vpc-a", "vpc-b"}}).Times(1).Return(output, nil)
+	return clientMock
+}
+
+func TestAssertVPCCIDRsDoNotOverlap_Disjoint(t *testing.T) {
+	// Setup
+	clientMock := newTestVPCCIDRsClient(t, "10.1.0.0/16")
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCCIDRsDoNotOverlap(fakeTest, context.Background(), clientMock, []string{"vpc-a", "vpc-b"})
+
+	// Assert
+	assert.False(t, fakeTest.Failed())
+}
+
+func TestAssertVPCCIDRsDoNotOverlap_Overlapping(t *testing.T) {
+	// Setup
+	clientMock := newTestVPCCIDRsClient(t, "10.0.128.0/17")
+	fakeTest := &testing.T{}
+
+	// Execute
+	AssertVPCCIDRsDoNotOverlap(fakeTest, context.Background(), clientMock, []string{"vpc-a", "vpc-b"})
+
+	// Assert
+	assert.True(t, fakeTest.Failed())
+}